@@ -0,0 +1,132 @@
+package newznab
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/smquartz/errors"
+)
+
+// Aggregator executes searches against several indexers concurrently, and
+// merges their results
+type Aggregator struct {
+	// clients of the indexers searched, keyed by a name identifying each
+	Clients map[string]*Client
+	// how long each indexer is given to respond; unlimited if zero
+	Timeout time.Duration
+	// how long a search of every indexer is given to complete; unlimited if
+	// zero. Indexers that have not responded by then are reported as failed.
+	Deadline time.Duration
+}
+
+// NewAggregator returns an Aggregator of the provided clients, keyed by a
+// name identifying each indexer
+func NewAggregator(clients map[string]*Client) *Aggregator {
+	return &Aggregator{Clients: clients}
+}
+
+// AggregateResults describes the merged results of a search of several
+// indexers
+type AggregateResults struct {
+	// entries returned by every indexer, ordered by the name of the indexer and
+	// then as the indexer returned them
+	Entries []Entry
+	// results of each indexer that was searched successfully, keyed by name
+	Results map[string]Results
+	// errors of each indexer that could not be searched, keyed by name
+	Failures map[string]error
+}
+
+// Search executes a generic search (t=search) against every indexer
+func (a *Aggregator) Search(ctx context.Context, q SearchQuery) (AggregateResults, error) {
+	return a.search(ctx, func(ctx context.Context, c *Client) (Results, error) {
+		return c.Search(ctx, q)
+	})
+}
+
+// TVSearch executes a TV search (t=tvsearch) against every indexer
+func (a *Aggregator) TVSearch(ctx context.Context, q TVQuery) (AggregateResults, error) {
+	return a.search(ctx, func(ctx context.Context, c *Client) (Results, error) {
+		return c.TVSearch(ctx, q)
+	})
+}
+
+// MovieSearch executes a movie search (t=movie) against every indexer
+func (a *Aggregator) MovieSearch(ctx context.Context, q MovieQuery) (AggregateResults, error) {
+	return a.search(ctx, func(ctx context.Context, c *Client) (Results, error) {
+		return c.MovieSearch(ctx, q)
+	})
+}
+
+// MusicSearch executes a music search (t=music) against every indexer
+func (a *Aggregator) MusicSearch(ctx context.Context, q MusicQuery) (AggregateResults, error) {
+	return a.search(ctx, func(ctx context.Context, c *Client) (Results, error) {
+		return c.MusicSearch(ctx, q)
+	})
+}
+
+// BookSearch executes a book search (t=book) against every indexer
+func (a *Aggregator) BookSearch(ctx context.Context, q BookQuery) (AggregateResults, error) {
+	return a.search(ctx, func(ctx context.Context, c *Client) (Results, error) {
+		return c.BookSearch(ctx, q)
+	})
+}
+
+// search executes the provided search against every indexer concurrently, and
+// merges the results. An error is returned only if every indexer failed;
+// otherwise failures are described in the AggregateResults.
+func (a *Aggregator) search(ctx context.Context, search func(context.Context, *Client) (Results, error)) (AggregateResults, error) {
+	aggregate := AggregateResults{Results: make(map[string]Results), Failures: make(map[string]error)}
+	if len(a.Clients) == 0 {
+		return aggregate, errors.Errorf("no indexers to search")
+	}
+	if a.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Deadline)
+		defer cancel()
+	}
+
+	names := make([]string, 0, len(a.Clients))
+	for name := range a.Clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string, client *Client) {
+			defer wg.Done()
+			ctx := ctx
+			if a.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+				defer cancel()
+			}
+
+			results, err := search(ctx, client)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				aggregate.Failures[name] = err
+				return
+			}
+			for i := range results.Entries {
+				results.Entries[i].Meta.Source.Indexer = name
+			}
+			aggregate.Results[name] = results
+		}(name, a.Clients[name])
+	}
+	wg.Wait()
+
+	for _, name := range names {
+		aggregate.Entries = append(aggregate.Entries, aggregate.Results[name].Entries...)
+	}
+	if len(aggregate.Failures) == len(names) {
+		return aggregate, errors.Errorf("every one of %d indexers failed, including %s: %v", len(names), names[0], aggregate.Failures[names[0]])
+	}
+	return aggregate, nil
+}
@@ -0,0 +1,57 @@
+package newznab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAggregatorSearch(t *testing.T) {
+	nzbsu := newTestServer("samples/newznab/newznab_nzb_su.xml", nil)
+	defer nzbsu.Close()
+	music := newTestServer("samples/newznab/newznab_music.xml", nil)
+	defer music.Close()
+	unauthorized := newTestServer("samples/newznab/unauthorized.xml", nil)
+	defer unauthorized.Close()
+	block := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+	}))
+	defer slow.Close()
+	defer close(block)
+
+	clients := make(map[string]*Client)
+	for name, server := range map[string]*httptest.Server{"nzbsu": nzbsu, "music": music, "unauthorized": unauthorized, "slow": slow} {
+		client, err := NewClient(server.URL, "xxx")
+		if err != nil {
+			t.Fatalf("Error creating client: %v", err)
+		}
+		clients[name] = client
+	}
+	aggregator := NewAggregator(clients)
+	aggregator.Timeout = 100 * time.Millisecond
+
+	aggregate, err := aggregator.Search(context.Background(), SearchQuery{Query: "test"})
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+
+	if len(aggregate.Entries) != 101 {
+		t.Fatalf("Wrong number of entries: %d", len(aggregate.Entries))
+	}
+	// entries are ordered by the name of the indexer
+	if aggregate.Entries[0].Meta.Source.Indexer != "music" || aggregate.Entries[1].Meta.Source.Indexer != "nzbsu" {
+		t.Errorf("Wrong indexers: %s, %s", aggregate.Entries[0].Meta.Source.Indexer, aggregate.Entries[1].Meta.Source.Indexer)
+	}
+	if len(aggregate.Results) != 2 || len(aggregate.Results["nzbsu"].Entries) != 100 {
+		t.Errorf("Wrong results: %v", aggregate.Results)
+	}
+	if err := aggregate.Failures["unauthorized"]; err != ErrIncorrectUserCredentials {
+		t.Errorf("Wrong error for unauthorized indexer: %v", err)
+	}
+	if err := aggregate.Failures["slow"]; err == nil {
+		t.Error("No error for slow indexer")
+	}
+}
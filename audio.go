@@ -1,5 +1,10 @@
 package newznab
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Music describes the music contained within a newznab entry
 type Music struct {
 	// embed common information
@@ -13,9 +18,51 @@ type Music struct {
 	Album string
 	// track listing
 	Tracks []string
+	// genre of the music
+	Genre string
+	// year the music was released
+	Year int
 }
 
 // Title returns the title of an album
 func (m Music) Title() string {
 	return m.Album
 }
+
+// ReleaseYear returns the year an album was released
+func (m Music) ReleaseYear() int {
+	return m.Year
+}
+
+// attrs adds the newznab attributes describing the Music Content to a list
+func (m Music) attrs(a *rssAttrs) {
+	a.add("artist", m.Artist)
+	a.add("album", m.Album)
+	a.add("tracks", strings.Join(m.Tracks, "|"))
+	a.add("genre", m.Genre)
+	a.addInt("year", int64(m.Year))
+	m.TVMovieMusic.attrs(a)
+	m.MusicBook.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (m *Music) setAttr(name, value string) bool {
+	switch name {
+	case "artist":
+		m.Artist = value
+	case "album":
+		m.Album = value
+	case "tracks":
+		m.Tracks = splitList(value, "|")
+	case "genre":
+		m.Genre = value
+	case "year":
+		if year, err := strconv.Atoi(value); err == nil {
+			m.Year = year
+		}
+	default:
+		return m.TVMovieMusic.setAttr(name, value) || m.MusicBook.setAttr(name, value)
+	}
+	return true
+}
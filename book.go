@@ -1,6 +1,7 @@
 package newznab
 
 import (
+	"strconv"
 	"time"
 )
 
@@ -23,3 +24,42 @@ type Book struct {
 func (b Book) Title() string {
 	return b.BookTitle
 }
+
+// ReleaseYear returns the year a Book was published
+func (b Book) ReleaseYear() int {
+	if b.Published.IsZero() {
+		return 0
+	}
+	return b.Published.Year()
+}
+
+// attrs adds the newznab attributes describing the Book Content to a list
+func (b Book) attrs(a *rssAttrs) {
+	a.add("booktitle", b.BookTitle)
+	a.add("author", b.Author)
+	a.addTime("publishdate", b.Published)
+	a.addInt("pages", int64(b.Pages))
+	b.MusicBook.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (b *Book) setAttr(name, value string) bool {
+	switch name {
+	case "booktitle":
+		b.BookTitle = value
+	case "author":
+		b.Author = value
+	case "publishdate":
+		if published, err := parseDate(value); err == nil {
+			b.Published = published
+		}
+	case "pages":
+		if pages, err := strconv.Atoi(value); err == nil {
+			b.Pages = pages
+		}
+	default:
+		return b.MusicBook.setAttr(name, value)
+	}
+	return true
+}
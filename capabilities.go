@@ -0,0 +1,401 @@
+package newznab
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/smquartz/errors"
+	nxml "github.com/smquartz/newznab/xml"
+)
+
+// DefaultCapabilitiesTTL is how long the capabilities of an indexer are cached
+// for if a Client does not specify otherwise
+const DefaultCapabilitiesTTL = 24 * time.Hour
+
+// cachedCapabilities describes the capabilities of an indexer, when they were
+// retrieved, and when they expire for the Client that retrieved them
+type cachedCapabilities struct {
+	caps    nxml.Capabilities
+	fetched time.Time
+	expires time.Time
+}
+
+// capabilitiesKey identifies the capabilities of an indexer as seen by a
+// particular account, which may be subject to different limits than others
+type capabilitiesKey struct {
+	endpoint string
+	apiKey   string
+}
+
+// CapabilitiesCache caches the capabilities of indexers, keyed by endpoint and
+// API key, so that Clients of the same account may share them. Its zero value
+// is an empty cache; expired capabilities are dropped whenever it is updated.
+type CapabilitiesCache struct {
+	mu      sync.Mutex
+	entries map[capabilitiesKey]cachedCapabilities
+}
+
+// get returns the cached capabilities for the provided key, if any
+func (cc *CapabilitiesCache) get(key capabilitiesKey) (cachedCapabilities, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cached, ok := cc.entries[key]
+	return cached, ok
+}
+
+// put caches capabilities under the provided key for the provided TTL, and
+// drops any which have expired
+func (cc *CapabilitiesCache) put(key capabilitiesKey, caps nxml.Capabilities, ttl time.Duration) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	now := time.Now()
+	if cc.entries == nil {
+		cc.entries = make(map[capabilitiesKey]cachedCapabilities)
+	}
+	for k, cached := range cc.entries {
+		if !now.Before(cached.expires) {
+			delete(cc.entries, k)
+		}
+	}
+	cc.entries[key] = cachedCapabilities{caps: caps, fetched: now, expires: now.Add(ttl)}
+}
+
+// capabilitiesKey returns the key the client's capabilities are cached under
+func (c *Client) capabilitiesKey() capabilitiesKey {
+	return capabilitiesKey{endpoint: c.endpoint.String(), apiKey: c.apiKey}
+}
+
+// capabilitiesTTL returns how long the client caches capabilities for
+func (c *Client) capabilitiesTTL() time.Duration {
+	if c.CapabilitiesTTL == 0 {
+		return DefaultCapabilitiesTTL
+	}
+	return c.CapabilitiesTTL
+}
+
+// Capabilities returns the capabilities of the indexer (t=caps), such as its
+// limits, retention, and categories. They are kept in the client's
+// CapabilitiesCache for its CapabilitiesTTL.
+func (c *Client) Capabilities(ctx context.Context) (nxml.Capabilities, error) {
+	if c.CapabilitiesCache != nil {
+		cached, ok := c.CapabilitiesCache.get(c.capabilitiesKey())
+		if ok && time.Since(cached.fetched) < c.capabilitiesTTL() {
+			return cached.caps, nil
+		}
+	}
+
+	return c.RefreshCapabilities(ctx)
+}
+
+// RefreshCapabilities retrieves the capabilities of the indexer (t=caps),
+// bypassing and then updating the cache
+func (c *Client) RefreshCapabilities(ctx context.Context) (nxml.Capabilities, error) {
+	var caps nxml.Capabilities
+	body, err := c.get(ctx, "caps", nil)
+	if err != nil {
+		return caps, err
+	}
+	if err := xml.Unmarshal(body, &caps); err != nil {
+		return caps, errors.Wrapf(err, "unable to parse caps response", 1)
+	}
+
+	if ttl := c.capabilitiesTTL(); ttl > 0 && c.CapabilitiesCache != nil {
+		c.CapabilitiesCache.put(c.capabilitiesKey(), caps, ttl)
+	}
+	return caps, nil
+}
+
+// CapabilitiesPolicy describes how a Client treats searches that the
+// capabilities of an indexer say it cannot serve
+type CapabilitiesPolicy int
+
+// policies for handling searches against an indexer's capabilities
+const (
+	// searches are sent as requested, without retrieving capabilities
+	CapabilitiesIgnore CapabilitiesPolicy = iota
+	// searches of an unavailable kind fail with ErrFunctionNotAvailable, and
+	// every search fails if the capabilities cannot be retrieved
+	CapabilitiesStrict
+	// searches of an unavailable kind are sent as a generic search instead,
+	// with their parameters folded into the free text query where possible
+	CapabilitiesFallback
+)
+
+// searchCapabilities returns the capabilities describing the provided search
+// function; known is false if the indexer's capabilities do not describe it
+func searchCapabilities(searching nxml.CapabilitiesSearching, function string) (search nxml.SearchCapabilities, known bool) {
+	switch function {
+	case "search":
+		return searching.General, true
+	case "tvsearch":
+		return searching.TV, true
+	case "movie":
+		return searching.Movie, true
+	case "music":
+		// music-search supersedes audio-search in newer indexers
+		if searching.Music.Declared {
+			return searching.Music, true
+		}
+		return searching.Audio, searching.Audio.Declared
+	case "book":
+		return searching.Book, searching.Book.Declared
+	}
+	return search, false
+}
+
+// searchParams are the API parameters subject to an indexer's supported
+// parameters
+var searchParams = []nxml.SearchParam{
+	nxml.ParamQuery, nxml.ParamSeason, nxml.ParamEpisode, nxml.ParamTVRageID,
+	nxml.ParamTVDBID, nxml.ParamTVMazeID, nxml.ParamIMDBID, nxml.ParamTMDBID,
+	nxml.ParamGenre, nxml.ParamArtist, nxml.ParamAlbum, nxml.ParamLabel,
+	nxml.ParamTrack, nxml.ParamYear, nxml.ParamTitle, nxml.ParamAuthor,
+}
+
+// searchServes returns whether a kind of search is available, and supports
+// every search parameter provided
+func searchServes(search nxml.SearchCapabilities, params url.Values) bool {
+	if !search.Available {
+		return false
+	}
+	for _, param := range searchParams {
+		if params.Get(string(param)) != "" && !search.Supports(param) {
+			return false
+		}
+	}
+	return true
+}
+
+// applyCapabilities adapts a search to the capabilities of the indexer
+// according to the client's CapabilitiesPolicy, and returns the function and
+// parameters that should actually be sent. Searches that are unavailable, or
+// use parameters the indexer does not support, fail or fall back. Limits above the indexer's maximum
+// are clamped, and categories the indexer does not have are removed.
+func (c *Client) applyCapabilities(ctx context.Context, function string, params url.Values) (string, url.Values, error) {
+	if c.CapabilitiesPolicy == CapabilitiesIgnore {
+		return function, params, nil
+	}
+	caps, err := c.Capabilities(ctx)
+	if err != nil {
+		if c.CapabilitiesPolicy == CapabilitiesStrict {
+			return function, params, errors.Wrapf(err, "unable to validate %s against capabilities", 1, function)
+		}
+		// without capabilities there is nothing to fall back against
+		return function, params, nil
+	}
+
+	if search, known := searchCapabilities(caps.Searching, function); known && !searchServes(search, params) {
+		if c.CapabilitiesPolicy != CapabilitiesFallback || function == "search" || !caps.Searching.General.Available {
+			return function, params, ErrFunctionNotAvailable
+		}
+		params, err = fallbackParams(function, params)
+		if err != nil {
+			return function, params, err
+		}
+		function = "search"
+	}
+
+	if limit, err := strconv.Atoi(params.Get("limit")); err == nil && caps.Limits.Max > 0 && limit > caps.Limits.Max {
+		params.Set("limit", strconv.Itoa(caps.Limits.Max))
+	}
+
+	if cat := params.Get("cat"); cat != "" && len(caps.Categories) > 0 {
+		indexed := make(map[string]bool)
+		for _, category := range caps.Categories {
+			indexed[strconv.Itoa(category.ID)] = true
+			for _, subcategory := range category.Subcategories {
+				indexed[strconv.Itoa(subcategory.ID)] = true
+			}
+		}
+		var codes []string
+		for _, code := range strings.Split(cat, ",") {
+			if indexed[code] {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) == 0 {
+			// searching without categories would return unwanted results
+			return function, params, ErrIncorrectParameter
+		}
+		params.Set("cat", strings.Join(codes, ","))
+	}
+
+	return function, params, nil
+}
+
+// fallbackParams converts the parameters of a typed search into those of a
+// generic search, by folding what it can into the free text query
+func fallbackParams(function string, params url.Values) (url.Values, error) {
+	terms := []string{params.Get("q")}
+	switch function {
+	case "tvsearch":
+		season, episode := params.Get("season"), params.Get("ep")
+		if len(season) == 4 {
+			// daily shows are named by air date, such as 2012.02.27
+			terms = append(terms, strings.TrimSuffix(season+"."+strings.Replace(episode, "/", ".", -1), "."))
+		} else if s, err := strconv.Atoi(season); err == nil {
+			if e, err := strconv.Atoi(episode); err == nil {
+				terms = append(terms, fmt.Sprintf("S%02dE%02d", s, e))
+			} else {
+				terms = append(terms, fmt.Sprintf("S%02d", s))
+			}
+		}
+	case "music":
+		terms = append(terms, params.Get("artist"), params.Get("album"), params.Get("track"))
+	case "book":
+		terms = append(terms, params.Get("author"), params.Get("title"))
+	}
+
+	query := strings.Join(strings.Fields(strings.Join(terms, " ")), " ")
+	if query == "" {
+		return params, ErrFunctionNotAvailable
+	}
+	fallback := url.Values{}
+	fallback.Set("q", query)
+	for _, key := range []string{"cat", "limit", "offset"} {
+		setString(fallback, key, params.Get(key))
+	}
+	return fallback, nil
+}
+
+// serverSearchParams are the parameters a Server parses for each kind of search
+var serverSearchParams = map[nxml.SearchType][]nxml.SearchParam{
+	nxml.SearchGeneral: {nxml.ParamQuery},
+	nxml.SearchTV: {nxml.ParamQuery, nxml.ParamSeason, nxml.ParamEpisode, nxml.ParamTVRageID,
+		nxml.ParamTVDBID, nxml.ParamTVMazeID, nxml.ParamIMDBID},
+	nxml.SearchMovie: {nxml.ParamQuery, nxml.ParamIMDBID, nxml.ParamGenre},
+	nxml.SearchMusic: {nxml.ParamQuery, nxml.ParamArtist, nxml.ParamAlbum, nxml.ParamLabel,
+		nxml.ParamTrack, nxml.ParamYear, nxml.ParamGenre},
+	nxml.SearchBook: {nxml.ParamQuery, nxml.ParamTitle, nxml.ParamAuthor},
+}
+
+// CapabilitiesBuilder builds the capabilities of an indexer, such as one served
+// by a Server, for answering t=caps
+type CapabilitiesBuilder struct {
+	// describes the indexer itself
+	Server nxml.CapabilitiesServer
+	// the limits imposed on searches
+	Limits nxml.CapabilitiesLimits
+	// how many days content is retained for; omitted if zero
+	RetentionDays int
+	// whether registration is available and open
+	Registration nxml.CapabilitiesRegistration
+	// the kinds of search that are available, mapped onto the parameters they
+	// accept; a nil slice accepts every parameter a Server parses for that kind
+	// of search. Other kinds of search are described as unavailable.
+	Searches map[nxml.SearchType][]nxml.SearchParam
+	// the categories indexed, along with the parents of any subcategories;
+	// every category defined by the newznab spec if empty
+	Categories []Category
+	// the usenet groups indexed
+	Groups []nxml.CapabilitiesGroup
+	// the genres indexed
+	Genres []nxml.CapabilitiesGenre
+}
+
+// NewCapabilitiesBuilder returns a CapabilitiesBuilder for an indexer with
+// the provided title, supporting the provided kinds of search
+func NewCapabilitiesBuilder(title string, searches ...nxml.SearchType) *CapabilitiesBuilder {
+	b := &CapabilitiesBuilder{
+		Server:   nxml.CapabilitiesServer{Version: "1.0", Title: title},
+		Limits:   nxml.CapabilitiesLimits{Max: 100, Default: 100},
+		Searches: make(map[nxml.SearchType][]nxml.SearchParam),
+	}
+	for _, search := range searches {
+		b.Searches[search] = nil
+	}
+	return b
+}
+
+// Build returns the capabilities described by the builder
+func (b CapabilitiesBuilder) Build() nxml.Capabilities {
+	caps := nxml.Capabilities{
+		Server:       b.Server,
+		Limits:       b.Limits,
+		Retention:    nxml.CapabilitiesRetention{Days: b.RetentionDays},
+		Registration: b.Registration,
+		Categories:   capabilitiesCategories(b.Categories),
+		Groups:       b.Groups,
+		Genres:       b.Genres,
+	}
+
+	searching := map[nxml.SearchType]*nxml.SearchCapabilities{
+		nxml.SearchGeneral: &caps.Searching.General,
+		nxml.SearchTV:      &caps.Searching.TV,
+		nxml.SearchMovie:   &caps.Searching.Movie,
+		nxml.SearchAudio:   &caps.Searching.Audio,
+		nxml.SearchMusic:   &caps.Searching.Music,
+		nxml.SearchBook:    &caps.Searching.Book,
+	}
+	for search, capabilities := range searching {
+		capabilities.Declared = true
+		params, ok := b.Searches[search]
+		if search == nxml.SearchAudio && !ok {
+			// older clients only know music searches as audio-search
+			params, ok = b.Searches[nxml.SearchMusic]
+			search = nxml.SearchMusic
+		}
+		if !ok {
+			continue
+		}
+		if params == nil {
+			params = serverSearchParams[search]
+		}
+		capabilities.Available, capabilities.SupportedParams = true, params
+	}
+	return caps
+}
+
+// capabilitiesCategories returns the categories of an indexer's capabilities
+// describing the provided categories and the parents of their subcategories,
+// or every category defined by the newznab spec if none are provided
+func capabilitiesCategories(indexed []Category) []nxml.CapabilitiesCategory {
+	if len(indexed) == 0 {
+		for _, category := range categoryTable {
+			indexed = append(indexed, category)
+		}
+	}
+
+	parents := make(map[int]*nxml.CapabilitiesCategory)
+	subcategories := make(map[int]bool)
+	parent := func(code int) *nxml.CapabilitiesCategory {
+		if _, ok := parents[code]; !ok {
+			parents[code] = &nxml.CapabilitiesCategory{ID: code, Name: CategoryFromCode(code).Text}
+		}
+		return parents[code]
+	}
+	for _, category := range indexed {
+		if category.Code <= 0 {
+			continue
+		}
+		if category.Code%1000 == 0 {
+			parent(category.Code)
+			continue
+		}
+		if subcategories[category.Code] {
+			continue
+		}
+		subcategories[category.Code] = true
+		p := parent(category.Code - category.Code%1000)
+		name := category.Text
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+		p.Subcategories = append(p.Subcategories, nxml.CapabilitiesCategory{ID: category.Code, Name: name})
+	}
+
+	var categories []nxml.CapabilitiesCategory
+	for _, p := range parents {
+		sort.Slice(p.Subcategories, func(i, j int) bool { return p.Subcategories[i].ID < p.Subcategories[j].ID })
+		categories = append(categories, *p)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories
+}
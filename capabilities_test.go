@@ -0,0 +1,267 @@
+package newznab
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	nxml "github.com/smquartz/newznab/xml"
+)
+
+func TestClientCapabilities(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("t") != "caps" {
+			t.Errorf("Wrong function: %s", r.URL.Query().Get("t"))
+		}
+		http.ServeFile(w, r, "samples/newznab/newznab_caps.xml")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		caps, err := client.Capabilities(context.Background())
+		if err != nil {
+			t.Fatalf("Error getting capabilities: %v", err)
+		}
+		if caps.Limits.Max != 60 {
+			t.Errorf("Wrong limits max: %d", caps.Limits.Max)
+		}
+		if len(caps.Categories) != 3 {
+			t.Errorf("Wrong number of categories: %d", len(caps.Categories))
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Capabilities not cached; %d calls made", calls)
+	}
+
+	other, err := NewClient(server.URL, "yyy")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	if _, err := other.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Error getting capabilities: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Capabilities shared between API keys; %d calls made", calls)
+	}
+
+	shared, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	if _, err := shared.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Error getting capabilities: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Capabilities shared between caches; %d calls made", calls)
+	}
+	shared.CapabilitiesCache = client.CapabilitiesCache
+	if _, err := shared.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Error getting capabilities: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Capabilities not shared through a cache; %d calls made", calls)
+	}
+
+	client.CapabilitiesTTL = -1
+	if _, err := client.Capabilities(context.Background()); err != nil {
+		t.Fatalf("Error getting capabilities: %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("Capabilities cached despite negative TTL; %d calls made", calls)
+	}
+}
+
+func TestCapabilitiesCachePrune(t *testing.T) {
+	var cache CapabilitiesCache
+	cache.put(capabilitiesKey{endpoint: "a"}, nxml.Capabilities{}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	cache.put(capabilitiesKey{endpoint: "b"}, nxml.Capabilities{}, time.Hour)
+	if _, ok := cache.get(capabilitiesKey{endpoint: "a"}); ok {
+		t.Error("Expired capabilities not pruned")
+	}
+	if _, ok := cache.get(capabilitiesKey{endpoint: "b"}); !ok {
+		t.Error("Capabilities not cached")
+	}
+}
+
+func TestApplyCapabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<caps>
+			<limits max="60" default="25"/>
+			<searching><search available="yes"/><tv-search available="no"/><movie-search available="yes"/><audio-search available="no"/></searching>
+			<categories><category id="5000" name="TV"><subcat id="5040" name="HD"/></category></categories>
+		</caps>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	client.CapabilitiesPolicy = CapabilitiesStrict
+	params, _ := TVQuery{Query: "White Collar", Season: "3", Episode: "5"}.values()
+	if _, _, err := client.applyCapabilities(context.Background(), "tvsearch", params); err != ErrFunctionNotAvailable {
+		t.Errorf("Wrong error for unavailable search: %v", err)
+	}
+
+	client.CapabilitiesPolicy = CapabilitiesFallback
+	params, _ = TVQuery{Query: "White Collar", Season: "3", Episode: "5", Categories: []Category{CategoryTVHD, CategoryTVUHD}, Limit: 100}.values()
+	function, params, err := client.applyCapabilities(context.Background(), "tvsearch", params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if function != "search" {
+		t.Errorf("Wrong fallback function: %s", function)
+	}
+	if params.Get("q") != "White Collar S03E05" {
+		t.Errorf("Wrong fallback query: %s", params.Get("q"))
+	}
+	if params.Get("cat") != "5040" {
+		t.Errorf("Wrong categories: %s", params.Get("cat"))
+	}
+	if params.Get("limit") != "60" {
+		t.Errorf("Wrong limit: %s", params.Get("limit"))
+	}
+
+	params, _ = MovieQuery{Categories: []Category{CategoryMoviesHD}}.values()
+	if _, _, err := client.applyCapabilities(context.Background(), "movie", params); err != ErrIncorrectParameter {
+		t.Errorf("Wrong error for unindexed categories: %v", err)
+	}
+}
+
+func TestApplyCapabilitiesUndeclared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<caps><searching><search available="yes"/></searching></caps>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "yyy")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	client.CapabilitiesPolicy = CapabilitiesStrict
+	// searches the capabilities do not describe are sent as requested
+	params, _ := MusicQuery{Artist: "Pink Floyd"}.values()
+	if function, _, err := client.applyCapabilities(context.Background(), "music", params); err != nil || function != "music" {
+		t.Errorf("Wrong music search without audio or music search: %s, %v", function, err)
+	}
+	params, _ = BookQuery{Author: "Frank Herbert"}.values()
+	if function, _, err := client.applyCapabilities(context.Background(), "book", params); err != nil || function != "book" {
+		t.Errorf("Wrong book search without book search: %s, %v", function, err)
+	}
+}
+
+func TestApplyCapabilitiesUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	params, _ := SearchQuery{Query: "White Collar"}.values()
+	client.CapabilitiesPolicy = CapabilitiesFallback
+	if _, _, err := client.applyCapabilities(context.Background(), "search", params); err != nil {
+		t.Errorf("Unexpected error without capabilities: %v", err)
+	}
+	client.CapabilitiesPolicy = CapabilitiesStrict
+	if _, _, err := client.applyCapabilities(context.Background(), "search", params); err == nil {
+		t.Errorf("No error without capabilities under strict policy")
+	}
+}
+
+func TestApplyCapabilitiesSupportedParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<caps><searching>
+			<search available="yes" supportedParams="q"/>
+			<tv-search available="yes" supportedParams="q,season,ep"/>
+			<book-search available="yes" supportedParams="q,title"/>
+		</searching></caps>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	client.CapabilitiesPolicy = CapabilitiesStrict
+
+	params, _ := TVQuery{Season: "3", Episode: "5"}.values()
+	if _, _, err := client.applyCapabilities(context.Background(), "tvsearch", params); err != nil {
+		t.Errorf("Unexpected error for supported parameters: %v", err)
+	}
+	params, _ = TVQuery{TVDBID: 108611}.values()
+	if _, _, err := client.applyCapabilities(context.Background(), "tvsearch", params); err != ErrFunctionNotAvailable {
+		t.Errorf("Wrong error for unsupported parameter: %v", err)
+	}
+
+	client.CapabilitiesPolicy = CapabilitiesFallback
+	params, _ = BookQuery{Title: "Dune", Author: "Frank Herbert"}.values()
+	function, params, err := client.applyCapabilities(context.Background(), "book", params)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if function != "search" || params.Get("q") != "Frank Herbert Dune" {
+		t.Errorf("Wrong fallback: %s %v", function, params)
+	}
+}
+
+func TestCapabilitiesBuilder(t *testing.T) {
+	builder := NewCapabilitiesBuilder("test", nxml.SearchGeneral, nxml.SearchTV, nxml.SearchMusic)
+	builder.Categories = []Category{CategoryTVHD, CategoryTVAnime, CategoryMovies, CategoryTVHD}
+	builder.Searches[nxml.SearchGeneral] = []nxml.SearchParam{nxml.ParamQuery}
+	caps := builder.Build()
+
+	expectedCategories := []nxml.CapabilitiesCategory{
+		{ID: 2000, Name: "Movies"},
+		{ID: 5000, Name: "TV", Subcategories: []nxml.CapabilitiesCategory{{ID: 5040, Name: "HD"}, {ID: 5070, Name: "Anime"}}},
+	}
+	if !reflect.DeepEqual(caps.Categories, expectedCategories) {
+		t.Errorf("Wrong categories: %+v", caps.Categories)
+	}
+	if len(NewCapabilitiesBuilder("test").Build().Categories) != 8 {
+		t.Errorf("Wrong number of default categories: %d", len(NewCapabilitiesBuilder("test").Build().Categories))
+	}
+
+	marshalled, err := xml.Marshal(caps)
+	if err != nil {
+		t.Fatalf("Error marshalling capabilities: %v", err)
+	}
+	var parsed nxml.Capabilities
+	if err := xml.Unmarshal(marshalled, &parsed); err != nil {
+		t.Fatalf("Error parsing capabilities: %v", err)
+	}
+	supports := []struct {
+		search   nxml.SearchType
+		param    nxml.SearchParam
+		expected bool
+	}{
+		{nxml.SearchGeneral, nxml.ParamQuery, true},
+		{nxml.SearchTV, nxml.ParamTVDBID, true},
+		{nxml.SearchTV, nxml.ParamTMDBID, false},
+		{nxml.SearchMovie, nxml.ParamQuery, false},
+		{nxml.SearchAudio, nxml.ParamArtist, true},
+		{nxml.SearchMusic, nxml.ParamArtist, true},
+		{nxml.SearchBook, nxml.ParamAuthor, false},
+	}
+	for _, s := range supports {
+		if parsed.Supports(s.search, s.param) != s.expected {
+			t.Errorf("Wrong support for %s in search %d: expected %v", s.param, s.search, s.expected)
+		}
+	}
+	if !parsed.Searching.Movie.Declared || parsed.Server.Title != "test" {
+		t.Errorf("Wrong capabilities: %+v", parsed)
+	}
+}
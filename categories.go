@@ -63,66 +63,68 @@ var (
 	CategoryOtherMisc         = Category{Code: 8010, Text: "Other/Misc"}
 )
 
+// categoryTable maps the codes of the categories defined by the newznab spec
+// onto their Category structs
+var categoryTable = map[int]Category{
+	0:    CategoryReserved,
+	1000: CategoryConsole,
+	1010: CategoryConsoleNDS,
+	1020: CategoryConsolePSP,
+	1030: CategoryConsoleWii,
+	1040: CategoryConsoleXbox,
+	1050: CategoryConsoleXbox360,
+	1060: CategoryConsoleWiiware,
+	1070: CategoryConsoleXbox360DLC,
+	2000: CategoryMovies,
+	2010: CategoryMoviesForeign,
+	2020: CategoryMoviesOther,
+	2030: CategoryMoviesSD,
+	2040: CategoryMoviesHD,
+	2045: CategoryMoviesUHD,
+	2050: CategoryMoviesBluRay,
+	2060: CategoryMovies3D,
+	3000: CategoryAudio,
+	3010: CategoryAudioMP3,
+	3020: CategoryAudioVideo,
+	3030: CategoryAudioAudiobook,
+	3040: CategoryAudioLossless,
+	4000: CategoryPC,
+	4010: CategoryPCZeroDay,
+	4020: CategoryPCISO,
+	4030: CategoryPCMac,
+	4040: CategoryPCMobileOther,
+	4050: CategoryPCGames,
+	4060: CategoryPCMobileiOS,
+	4070: CategoryPCMobileAndroid,
+	5000: CategoryTV,
+	5020: CategoryTVForeign,
+	5030: CategoryTVSD,
+	5040: CategoryTVHD,
+	5045: CategoryTVUHD,
+	5050: CategoryTVOther,
+	5060: CategoryTVSport,
+	5070: CategoryTVAnime,
+	5080: CategoryTVDocumentary,
+	6000: CategoryXXX,
+	6010: CategoryXXXDVD,
+	6020: CategoryXXXWMV,
+	6030: CategoryXXXXvid,
+	6040: CategoryXXXx264,
+	6050: CategoryXXXPack,
+	6060: CategoryXXXImageSet,
+	6070: CategoryXXXOther,
+	7000: CategoryBooks,
+	7010: CategoryBooksMags,
+	7020: CategoryBooksEbook,
+	7030: CategoryBooksComics,
+	8000: CategoryOther,
+	8010: CategoryOtherMisc,
+}
+
 // CategoryFromCode takes an integer category code, and returns the
 // corresponding Category struct
 func CategoryFromCode(code int) Category {
-
-	var categories = map[int]Category{
-		0:    CategoryReserved,
-		1000: CategoryConsole,
-		1010: CategoryConsoleNDS,
-		1020: CategoryConsolePSP,
-		1030: CategoryConsoleWii,
-		1040: CategoryConsoleXbox,
-		1050: CategoryConsoleXbox360,
-		1060: CategoryConsoleWiiware,
-		1070: CategoryConsoleXbox360DLC,
-		2000: CategoryMovies,
-		2010: CategoryMoviesForeign,
-		2020: CategoryMoviesOther,
-		2030: CategoryMoviesSD,
-		2040: CategoryMoviesHD,
-		2045: CategoryMoviesUHD,
-		2050: CategoryMoviesBluRay,
-		2060: CategoryMovies3D,
-		3000: CategoryAudio,
-		3010: CategoryAudioMP3,
-		3020: CategoryAudioVideo,
-		3030: CategoryAudioAudiobook,
-		3040: CategoryAudioLossless,
-		4000: CategoryPC,
-		4010: CategoryPCZeroDay,
-		4020: CategoryPCISO,
-		4030: CategoryPCMac,
-		4040: CategoryPCMobileOther,
-		4050: CategoryPCGames,
-		4060: CategoryPCMobileiOS,
-		4070: CategoryPCMobileAndroid,
-		5000: CategoryTV,
-		5020: CategoryTVForeign,
-		5030: CategoryTVSD,
-		5040: CategoryTVHD,
-		5045: CategoryTVUHD,
-		5050: CategoryTVOther,
-		5060: CategoryTVSport,
-		5070: CategoryTVAnime,
-		5080: CategoryTVDocumentary,
-		6000: CategoryXXX,
-		6010: CategoryXXXDVD,
-		6020: CategoryXXXWMV,
-		6030: CategoryXXXXvid,
-		6040: CategoryXXXx264,
-		6050: CategoryXXXPack,
-		6060: CategoryXXXImageSet,
-		6070: CategoryXXXOther,
-		7000: CategoryBooks,
-		7010: CategoryBooksMags,
-		7020: CategoryBooksEbook,
-		7030: CategoryBooksComics,
-		8000: CategoryOther,
-		8010: CategoryOtherMisc,
-	}
-	if category, ok := categories[code]; ok {
+	if category, ok := categoryTable[code]; ok {
 		return category
 	}
 	return Category{Code: code}
@@ -0,0 +1,190 @@
+package newznab
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/smquartz/errors"
+)
+
+// Client is a client for the API of a newznab indexer
+type Client struct {
+	// HTTP client used to issue requests; http.DefaultClient is used if nil
+	HTTPClient *http.Client
+	// how long the indexer's capabilities are cached for; DefaultCapabilitiesTTL
+	// is used if zero, and capabilities are never cached if negative
+	CapabilitiesTTL time.Duration
+	// cache the indexer's capabilities are kept in, which may be shared with
+	// other Clients; NewClient gives each Client its own, and none is used if nil
+	CapabilitiesCache *CapabilitiesCache
+	// how searches the indexer's capabilities say it cannot serve are treated
+	CapabilitiesPolicy CapabilitiesPolicy
+	// whether a feed containing any item that cannot be parsed fails as a
+	// whole, rather than the item being reported in the Results' Errors
+	Strict bool
+	// the API endpoint of the indexer
+	endpoint *url.URL
+	// API key used to authenticate against the endpoint
+	apiKey string
+}
+
+// NewClient returns a Client for the newznab indexer with the provided API
+// endpoint, which authenticates using the provided API key. If the endpoint
+// has no path, the conventional /api path is used.
+func NewClient(endpoint, apiKey string) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to parse endpoint %s", 1, endpoint)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("endpoint %s is not an absolute URL", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/api"
+	}
+	return &Client{CapabilitiesCache: new(CapabilitiesCache), endpoint: u, apiKey: apiKey}, nil
+}
+
+// Endpoint returns a copy of the API endpoint the client calls
+func (c *Client) Endpoint() *url.URL {
+	u := *c.endpoint
+	return &u
+}
+
+// Search executes a generic search (t=search) against the indexer, and returns
+// the parsed entries
+func (c *Client) Search(ctx context.Context, q SearchQuery) (Results, error) {
+	params, err := q.values()
+	if err != nil {
+		return Results{}, err
+	}
+	return c.entries(ctx, "search", params, contentAuto)
+}
+
+// TVSearch executes a TV search (t=tvsearch) against the indexer, and returns
+// the parsed entries, the Content of which is always TV
+func (c *Client) TVSearch(ctx context.Context, q TVQuery) (Results, error) {
+	params, err := q.values()
+	if err != nil {
+		return Results{}, err
+	}
+	return c.entries(ctx, "tvsearch", params, contentTV)
+}
+
+// MovieSearch executes a movie search (t=movie) against the indexer, and
+// returns the parsed entries, the Content of which is always a Movie
+func (c *Client) MovieSearch(ctx context.Context, q MovieQuery) (Results, error) {
+	params, err := q.values()
+	if err != nil {
+		return Results{}, err
+	}
+	return c.entries(ctx, "movie", params, contentMovie)
+}
+
+// MusicSearch executes a music search (t=music) against the indexer, and
+// returns the parsed entries, the Content of which is always Music
+func (c *Client) MusicSearch(ctx context.Context, q MusicQuery) (Results, error) {
+	params, err := q.values()
+	if err != nil {
+		return Results{}, err
+	}
+	return c.entries(ctx, "music", params, contentMusic)
+}
+
+// BookSearch executes a book search (t=book) against the indexer, and returns
+// the parsed entries, the Content of which is always a Book
+func (c *Client) BookSearch(ctx context.Context, q BookQuery) (Results, error) {
+	params, err := q.values()
+	if err != nil {
+		return Results{}, err
+	}
+	return c.entries(ctx, "book", params, contentBook)
+}
+
+// entries calls an API function that returns an RSS feed, and returns the
+// entries parsed from it, with Content of the provided kind
+func (c *Client) entries(ctx context.Context, function string, params url.Values, kind contentKind) (Results, error) {
+	function, params, err := c.applyCapabilities(ctx, function, params)
+	if err != nil {
+		return Results{}, err
+	}
+	// request every attribute the indexer knows of, rather than the default subset
+	params.Set("extended", "1")
+	body, err := c.get(ctx, function, params)
+	if err != nil {
+		return Results{}, err
+	}
+
+	feed, err := gofeed.NewParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return Results{}, errors.Wrapf(err, "unable to parse %s response", 1, function)
+	}
+	results, err := resultsFromFeed(*feed, kind, c.Strict)
+	if err != nil {
+		return Results{}, err
+	}
+	for i := range results.Entries {
+		results.Entries[i].Meta.Source.Endpoint = c.Endpoint()
+		results.Entries[i].Meta.Source.APIKey = c.apiKey
+	}
+	results.Function, results.Params = function, params
+	return results, nil
+}
+
+// get calls an API function with the provided parameters, and returns the raw
+// response body. If the indexer responds with a newznab error document, the
+// corresponding NError or NErrorRange is returned.
+func (c *Client) get(ctx context.Context, function string, params url.Values) ([]byte, error) {
+	u := c.Endpoint()
+	query := url.Values{}
+	for k, v := range params {
+		query[k] = v
+	}
+	query.Set("t", function)
+	if c.apiKey != "" {
+		query.Set("apikey", c.apiKey)
+	}
+	u.RawQuery = query.Encode()
+
+	body, _, err := c.download(ctx, u, function)
+	return body, err
+}
+
+// download requests the provided URL, and returns the raw response body and
+// its content type. The description is used in error messages. If the
+// indexer responds with a newznab error document, the corresponding NError or
+// NErrorRange is returned.
+func (c *Client) download(ctx context.Context, u *url.URL, description string) ([]byte, string, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "unable to create %s request", 1, description)
+	}
+	req = req.WithContext(ctx)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "unable to call %s", 1, description)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "unable to read %s response", 1, description)
+	}
+	if nerr := nerrorFromResponse(body); nerr != nil {
+		return nil, "", nerr
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, "", errors.Errorf("%s request failed with status %s", description, resp.Status)
+	}
+	return body, resp.Header.Get("Content-Type"), nil
+}
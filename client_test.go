@@ -0,0 +1,126 @@
+package newznab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestServer returns a test server that responds to every request with the
+// contents of the sample at the provided path, and records the query of the
+// last request in query
+func newTestServer(samplePath string, query *map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if query != nil {
+			*query = make(map[string]string)
+			for k := range r.URL.Query() {
+				(*query)[k] = r.URL.Query().Get(k)
+			}
+		}
+		http.ServeFile(w, r, samplePath)
+	}))
+}
+
+func TestClientSearch(t *testing.T) {
+	var query map[string]string
+	server := newTestServer("samples/newznab/newznab_nzb_su.xml", &query)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	results, err := client.Search(context.Background(), SearchQuery{Query: "white collar", Categories: []Category{CategoryTVHD}})
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	entries := results.Entries
+
+	expectedQuery := map[string]string{"t": "search", "apikey": "xxx", "q": "white collar", "cat": "5040", "extended": "1"}
+	for k, v := range expectedQuery {
+		if query[k] != v {
+			t.Errorf("Wrong %s parameter: %s", k, query[k])
+		}
+	}
+
+	if len(entries) != 100 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	if entries[0].Meta.Source.APIKey != "xxx" {
+		t.Errorf("Wrong API key: %s", entries[0].Meta.Source.APIKey)
+	}
+	if u := entries[0].Meta.Source.Endpoint; u == nil || u.String() != server.URL+"/api" {
+		t.Errorf("Wrong endpoint: %v", u)
+	}
+}
+
+func TestClientTVSearch(t *testing.T) {
+	var query map[string]string
+	server := newTestServer("samples/newznab/newznab_nzb_su.xml", &query)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	results, err := client.TVSearch(context.Background(), TVQuery{Season: "S03", Episode: "E05", TVDBID: 108611})
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	entries := results.Entries
+
+	expectedQuery := map[string]string{"t": "tvsearch", "season": "03", "ep": "05", "tvdbid": "108611"}
+	for k, v := range expectedQuery {
+		if query[k] != v {
+			t.Errorf("Wrong %s parameter: %s", k, query[k])
+		}
+	}
+
+	tv, ok := entries[0].Content.(TV)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entries[0].Content)
+	}
+	// indexers return loosely matched results, so they are not labelled with
+	// the IDs searched for
+	if tv.TVDBID != 0 {
+		t.Errorf("Wrong TVDB ID: %d", tv.TVDBID)
+	}
+	if results.Function != "tvsearch" || results.Params.Get("tvdbid") != "108611" {
+		t.Errorf("Wrong search: %s, %v", results.Function, results.Params)
+	}
+}
+
+func TestClientMovieSearch(t *testing.T) {
+	var query map[string]string
+	server := newTestServer("samples/newznab/newznab_nzb_su.xml", &query)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	results, err := client.MovieSearch(context.Background(), MovieQuery{IMDBID: "tt0133093", Genre: "Sci-Fi"})
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	entries := results.Entries
+
+	expectedQuery := map[string]string{"t": "movie", "imdbid": "0133093", "genre": "Sci-Fi"}
+	for k, v := range expectedQuery {
+		if query[k] != v {
+			t.Errorf("Wrong %s parameter: %s", k, query[k])
+		}
+	}
+
+	movie, ok := entries[0].Content.(Movie)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entries[0].Content)
+	}
+	if movie.IMDBEntry.ImdbID != "" {
+		t.Errorf("Wrong IMDB ID: %s", movie.IMDBEntry.ImdbID)
+	}
+	if results.Function != "movie" || results.Params.Get("imdbid") != "0133093" {
+		t.Errorf("Wrong search: %s, %v", results.Function, results.Params)
+	}
+}
@@ -8,3 +8,120 @@ type Content interface {
 	// year the content was released
 	ReleaseYear() int
 }
+
+// contentKind describes which Content implementation an entry's attributes
+// are parsed into
+type contentKind int
+
+// kinds of Content
+const (
+	// the Content implementation is inferred from the attributes present
+	contentAuto contentKind = iota
+	// the Content is always TV
+	contentTV
+	// the Content is always a Movie
+	contentMovie
+	// the Content is always Music
+	contentMusic
+	// the Content is always a Book
+	contentBook
+)
+
+// contentAttrKinds maps attributes that are specific to one Content
+// implementation onto its kind, so that the kind may be inferred
+var contentAttrKinds = map[string]contentKind{
+	"season":      contentTV,
+	"episode":     contentTV,
+	"rageid":      contentTV,
+	"tvdbid":      contentTV,
+	"tvmazeid":    contentTV,
+	"tvtitle":     contentTV,
+	"tvairdate":   contentTV,
+	"imdbtitle":   contentMovie,
+	"imdbyear":    contentMovie,
+	"artist":      contentMusic,
+	"album":       contentMusic,
+	"tracks":      contentMusic,
+	"booktitle":   contentBook,
+	"author":      contentBook,
+	"publishdate": contentBook,
+	"pages":       contentBook,
+}
+
+// contentKindFromCategories infers the kind of Content from the categories of
+// an entry
+func contentKindFromCategories(categories []Category) contentKind {
+	for _, category := range categories {
+		switch {
+		case category == CategoryAudioAudiobook:
+			return contentBook
+		case category.Code >= CategoryMovies.Code && category.Code < CategoryAudio.Code:
+			return contentMovie
+		case category.Code >= CategoryAudio.Code && category.Code < CategoryPC.Code:
+			return contentMusic
+		case category.Code >= CategoryTV.Code && category.Code < CategoryXXX.Code:
+			return contentTV
+		case category.Code >= CategoryBooks.Code && category.Code < CategoryOther.Code:
+			return contentBook
+		}
+	}
+	return contentAuto
+}
+
+// contentBuilder accumulates newznab attributes into every Content
+// implementation at once, so that attributes may arrive in any order, and the
+// implementation is only chosen once every attribute has been seen
+type contentBuilder struct {
+	// the kind of Content requested
+	kind contentKind
+	// the kind of Content inferred from the first specific attribute seen
+	inferred contentKind
+
+	tv    TV
+	movie Movie
+	music Music
+	book  Book
+}
+
+// newContentBuilder returns a contentBuilder for Content of the provided kind
+func newContentBuilder(kind contentKind) *contentBuilder {
+	return &contentBuilder{kind: kind}
+}
+
+// setAttr records a newznab attribute against every Content implementation,
+// and returns whether any of them recognised it
+func (b *contentBuilder) setAttr(name, value string) bool {
+	if kind, ok := contentAttrKinds[name]; ok && b.inferred == contentAuto {
+		b.inferred = kind
+	}
+	recognised := b.tv.setAttr(name, value)
+	recognised = b.movie.setAttr(name, value) || recognised
+	recognised = b.music.setAttr(name, value) || recognised
+	recognised = b.book.setAttr(name, value) || recognised
+	return recognised
+}
+
+// content returns the built Content; its kind is the one requested, or else
+// inferred from the attributes seen, or else from the provided categories. nil
+// is returned if no kind can be determined.
+func (b *contentBuilder) content(categories []Category) Content {
+	kind := b.kind
+	if kind == contentAuto {
+		kind = b.inferred
+	}
+	if kind == contentAuto {
+		kind = contentKindFromCategories(categories)
+	}
+
+	switch kind {
+	case contentTV:
+		return b.tv
+	case contentMovie:
+		return b.movie
+	case contentMusic:
+		return b.music
+	case contentBook:
+		return b.book
+	}
+	return nil
+}
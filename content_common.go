@@ -1,6 +1,10 @@
 package newznab
 
-import "net/url"
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
 
 // TVMovieMusicBook describes information common to TV, Movie, Music,
 // and Book Content implementations
@@ -57,3 +61,121 @@ type TVMovie struct {
 	// genre of the content
 	Genre string
 }
+
+// attrs adds the newznab attributes describing the information to a list
+func (t TVMovieMusicBook) attrs(a *rssAttrs) {
+	a.add("coverurl", t.CoverImage.String())
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (t *TVMovieMusicBook) setAttr(name, value string) bool {
+	switch name {
+	case "coverurl":
+		if u, err := url.Parse(value); err == nil {
+			t.CoverImage = *u
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// attrs adds the newznab attributes describing the information to a list
+func (t TVMovieMusic) attrs(a *rssAttrs) {
+	a.add("backdropcoverurl", t.BackdropCoverImage.String())
+	a.add("audio", t.AudioCodec)
+	a.add("language", strings.Join(t.Languages, ", "))
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (t *TVMovieMusic) setAttr(name, value string) bool {
+	switch name {
+	case "backdropcoverurl":
+		if u, err := url.Parse(value); err == nil {
+			t.BackdropCoverImage = *u
+		}
+	case "audio":
+		t.AudioCodec = value
+	case "language":
+		t.Languages = splitList(value, ",")
+	default:
+		return false
+	}
+	return true
+}
+
+// attrs adds the newznab attributes describing the information to a list
+func (m MovieMusicBook) attrs(a *rssAttrs) {
+	a.addFloat("review", float64(m.ReviewScore), 32)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (m *MovieMusicBook) setAttr(name, value string) bool {
+	switch name {
+	case "review":
+		if score, err := strconv.ParseFloat(value, 32); err == nil {
+			m.ReviewScore = float32(score)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// attrs adds the newznab attributes describing the information to a list
+func (m MusicBook) attrs(a *rssAttrs) {
+	a.add("publisher", m.Publisher)
+	m.MovieMusicBook.attrs(a)
+	m.TVMovieMusicBook.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (m *MusicBook) setAttr(name, value string) bool {
+	switch name {
+	case "publisher":
+		m.Publisher = value
+	default:
+		return m.MovieMusicBook.setAttr(name, value) || m.TVMovieMusicBook.setAttr(name, value)
+	}
+	return true
+}
+
+// attrs adds the newznab attributes describing the information to a list
+func (t TVMovie) attrs(a *rssAttrs) {
+	a.add("video", t.VideoCodec)
+	a.add("resolution", t.Resolution)
+	a.addFloat("framerate", float64(t.Framerate), 32)
+	a.add("subs", strings.Join(t.Subtitles, ", "))
+	a.add("genre", t.Genre)
+	t.TVMovieMusic.attrs(a)
+	t.TVMovieMusicBook.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (t *TVMovie) setAttr(name, value string) bool {
+	switch name {
+	case "video":
+		t.VideoCodec = value
+	case "resolution":
+		t.Resolution = value
+	case "framerate":
+		// framerates are sometimes suffixed with their unit
+		if fields := strings.Fields(value); len(fields) > 0 {
+			if framerate, err := strconv.ParseFloat(fields[0], 32); err == nil {
+				t.Framerate = float32(framerate)
+			}
+		}
+	case "subs":
+		t.Subtitles = splitList(value, ",")
+	case "genre":
+		t.Genre = value
+	default:
+		return t.TVMovieMusic.setAttr(name, value) || t.TVMovieMusicBook.setAttr(name, value)
+	}
+	return true
+}
@@ -0,0 +1,241 @@
+package newznab
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/anacrolix/torrent/metainfo"
+)
+
+// Deduplicator collapses entries describing the same release, such as those
+// returned by several indexers, into a single result
+type Deduplicator struct {
+	// how much the sizes of duplicates may differ, relative to the larger;
+	// 0.01 permits a difference of 1%
+	SizeTolerance float64
+	// how far apart the usenet dates of duplicates may be
+	DateTolerance time.Duration
+}
+
+// DefaultDeduplicator is the Deduplicator used by Deduplicate
+var DefaultDeduplicator = Deduplicator{SizeTolerance: 0.01, DateTolerance: 24 * time.Hour}
+
+// Duplicates describes a single release, and every entry describing it
+type Duplicates struct {
+	// the entry chosen to represent the release; the first of Sources
+	Entry Entry
+	// every entry describing the release, including Entry
+	Sources []Entry
+}
+
+// Deduplicate collapses duplicate entries using the DefaultDeduplicator
+func Deduplicate(entries []Entry) []Duplicates {
+	return DefaultDeduplicator.Deduplicate(entries)
+}
+
+// Deduplicate collapses entries describing the same release into Duplicates.
+// Torrents with the same info hash are always duplicates, and those with
+// different info hashes never are. Other entries are duplicates if their
+// normalised release names match, and their sizes and usenet dates are within
+// the Deduplicator's tolerances where known.
+//
+// The result does not depend on the order of the entries provided. Sources
+// are ordered by indexer, endpoint, GUID, and then the details of their
+// releases and files, and Duplicates by normalised release name and then the
+// order of their representative entries.
+func (d Deduplicator) Deduplicate(entries []Entry) []Duplicates {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sourceLess(sorted[i], sorted[j])
+	})
+
+	// entries are grouped using a disjoint set, so that duplicates of
+	// duplicates are collapsed whatever the order they are compared in
+	parents := make([]int, len(sorted))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+	// the info hash held by each root's group, if any; groups holding
+	// different info hashes are never merged, even through an entry that is a
+	// duplicate of both
+	hashes := make(map[int]metainfo.Hash)
+	union := func(i, j int) {
+		i, j = find(i), find(j)
+		if i == j {
+			return
+		}
+		hash, ok := hashes[i]
+		if hashJ, okJ := hashes[j]; okJ {
+			if ok && hash != hashJ {
+				return
+			}
+			hash, ok = hashJ, true
+		}
+		// the earliest entry is the root, and so represents the release
+		if j < i {
+			i, j = j, i
+		}
+		parents[j] = i
+		if ok {
+			hashes[i] = hash
+		}
+	}
+
+	names := make([]string, len(sorted))
+	byName := make(map[string][]int)
+	byHash := make(map[metainfo.Hash]int)
+	for i, entry := range sorted {
+		names[i] = normaliseReleaseName(entry.Release.Name)
+		if hash, ok := entryInfoHash(entry); ok {
+			hashes[i] = hash
+			if first, seen := byHash[hash]; seen {
+				union(first, i)
+			} else {
+				byHash[hash] = i
+			}
+		}
+		for _, j := range byName[names[i]] {
+			if d.duplicates(sorted[j], entry) {
+				union(j, i)
+			}
+		}
+		byName[names[i]] = append(byName[names[i]], i)
+	}
+
+	var roots []int
+	groups := make(map[int][]Entry)
+	for i, entry := range sorted {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], entry)
+	}
+	sort.SliceStable(roots, func(i, j int) bool {
+		return names[roots[i]] < names[roots[j]]
+	})
+
+	duplicates := make([]Duplicates, len(roots))
+	for i, root := range roots {
+		duplicates[i] = Duplicates{Entry: groups[root][0], Sources: groups[root]}
+	}
+	return duplicates
+}
+
+// duplicates returns whether two entries describe the same release
+func (d Deduplicator) duplicates(a, b Entry) bool {
+	hashA, okA := entryInfoHash(a)
+	hashB, okB := entryInfoHash(b)
+	if okA && okB {
+		return hashA == hashB
+	}
+	if normaliseReleaseName(a.Release.Name) != normaliseReleaseName(b.Release.Name) {
+		return false
+	}
+
+	if a.File != nil && b.File != nil {
+		sizeA, sizeB := float64(a.File.Size()), float64(b.File.Size())
+		if sizeA > 0 && sizeB > 0 && math.Abs(sizeA-sizeB) > d.SizeTolerance*math.Max(sizeA, sizeB) {
+			return false
+		}
+	}
+
+	dateA, dateB := a.Meta.Dates.PublishedUsenet, b.Meta.Dates.PublishedUsenet
+	if !dateA.IsZero() && !dateB.IsZero() {
+		difference := dateA.Sub(dateB)
+		if difference < 0 {
+			difference = -difference
+		}
+		if difference > d.DateTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// entryInfoHash returns the info hash of an entry's torrent; ok is false if
+// the entry is not a torrent or its info hash is unknown
+func entryInfoHash(entry Entry) (hash metainfo.Hash, ok bool) {
+	torrent, ok := entry.File.(*Torrent)
+	if !ok || torrent == nil {
+		return hash, false
+	}
+	hash = torrent.InfoHash()
+	return hash, hash != metainfo.Hash{}
+}
+
+// normaliseReleaseName returns a release name in a form that is the same
+// however an indexer formats it; lower case, with runs of punctuation and
+// whitespace replaced by a single space
+func normaliseReleaseName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// sourceLess orders entries by indexer, endpoint, and GUID, and then by
+// release name, download URL, info hash, size, and publication date for
+// entries without GUIDs, so that the sources of Duplicates are ordered
+// reproducibly
+func sourceLess(a, b Entry) bool {
+	if a.Meta.Source.Indexer != b.Meta.Source.Indexer {
+		return a.Meta.Source.Indexer < b.Meta.Source.Indexer
+	}
+	endpointA, endpointB := "", ""
+	if a.Meta.Source.Endpoint != nil {
+		endpointA = a.Meta.Source.Endpoint.String()
+	}
+	if b.Meta.Source.Endpoint != nil {
+		endpointB = b.Meta.Source.Endpoint.String()
+	}
+	if endpointA != endpointB {
+		return endpointA < endpointB
+	}
+	if guidA, guidB := a.Meta.GUID.String(), b.Meta.GUID.String(); guidA != guidB {
+		return guidA < guidB
+	}
+	if a.Release.Name != b.Release.Name {
+		return a.Release.Name < b.Release.Name
+	}
+	if urlA, urlB := entryDownloadURL(a), entryDownloadURL(b); urlA != urlB {
+		return urlA < urlB
+	}
+	hashA, _ := entryInfoHash(a)
+	hashB, _ := entryInfoHash(b)
+	if hashA != hashB {
+		return bytes.Compare(hashA[:], hashB[:]) < 0
+	}
+	if sizeA, sizeB := entrySize(a), entrySize(b); sizeA != sizeB {
+		return sizeA < sizeB
+	}
+	return a.Meta.Dates.Published.Before(b.Meta.Dates.Published)
+}
+
+// entryDownloadURL returns the URL an entry's file is downloaded from, or an
+// empty string if it is unknown
+func entryDownloadURL(entry Entry) string {
+	if entry.File == nil || entry.File.URL() == nil {
+		return ""
+	}
+	return entry.File.URL().String()
+}
+
+// entrySize returns the size of an entry's file, or -1 if it is unknown
+func entrySize(entry Entry) int64 {
+	if entry.File == nil {
+		return -1
+	}
+	return entry.File.Size()
+}
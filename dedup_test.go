@@ -0,0 +1,131 @@
+package newznab
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+func TestDeduplicate(t *testing.T) {
+	usenetDate := time.Date(2012, 2, 27, 12, 0, 0, 0, time.UTC)
+	entry := func(indexer, name string, size int64, date time.Time, hash string) Entry {
+		var e Entry
+		e.Meta.GUID = uuid.NewV5(uuid.NamespaceURL, fmt.Sprint(indexer, name, size, date, hash))
+		e.Meta.Source.Indexer = indexer
+		e.Meta.Dates.PublishedUsenet = date
+		e.Release.Name = name
+		if hash != "" {
+			torrent := new(Torrent)
+			torrent.setAttr("infohash", hash)
+			e.File = torrent
+		} else {
+			e.File = new(NZB)
+		}
+		e.File.setSize(size)
+		return e
+	}
+
+	entries := []Entry{
+		entry("b", "White.Collar.S03E05.720p.HDTV.X264-DIMENSION", 1183105773, usenetDate, ""),
+		entry("a", "White Collar S03E05 720p HDTV x264-DIMENSION", 1183000000, usenetDate.Add(time.Hour), ""),
+		// too different in size
+		entry("c", "White.Collar.S03E05.720p.HDTV.X264-DIMENSION", 900000000, usenetDate, ""),
+		// too different in date
+		entry("d", "White.Collar.S03E05.720p.HDTV.X264-DIMENSION", 1183105773, usenetDate.Add(72*time.Hour), ""),
+		entry("a", "Archer.2009.S03E07.HDTV.XviD-LOL", 367001600, usenetDate, "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"),
+		// the same torrent under a different name
+		entry("b", "Archer S03E07", 0, time.Time{}, "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"),
+		// a different torrent under the same name
+		entry("c", "Archer.2009.S03E07.HDTV.XviD-LOL", 367001600, usenetDate, "0123456789abcdef0123456789abcdef01234567"),
+	}
+
+	duplicates := Deduplicate(entries)
+	expected := [][]string{{"a", "b"}, {"c"}, {"a", "b"}, {"c"}, {"d"}}
+	if len(duplicates) != len(expected) {
+		t.Fatalf("Wrong number of duplicates: %d", len(duplicates))
+	}
+	for i, indexers := range expected {
+		if len(duplicates[i].Sources) != len(indexers) {
+			t.Errorf("Wrong number of sources for %d: %d", i, len(duplicates[i].Sources))
+			continue
+		}
+		for j, indexer := range indexers {
+			if actual := duplicates[i].Sources[j].Meta.Source.Indexer; actual != indexer {
+				t.Errorf("Wrong indexer for source %d of %d: %s", j, i, actual)
+			}
+		}
+		if duplicates[i].Entry.Meta.GUID != duplicates[i].Sources[0].Meta.GUID {
+			t.Errorf("Wrong representative entry for %d", i)
+		}
+	}
+
+	// torrents with different info hashes are not duplicates, even through an
+	// entry without one that is a duplicate of both
+	chained := Deduplicate([]Entry{
+		entry("a", "Archer.2009.S03E07.HDTV.XviD-LOL", 367001600, usenetDate, "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"),
+		entry("b", "Archer.2009.S03E07.HDTV.XviD-LOL", 367001600, usenetDate, ""),
+		entry("c", "Archer.2009.S03E07.HDTV.XviD-LOL", 367001600, usenetDate, "0123456789abcdef0123456789abcdef01234567"),
+	})
+	if len(chained) != 2 || len(chained[0].Sources)+len(chained[1].Sources) != 3 {
+		t.Errorf("Wrong duplicates for chained entries: %d", len(chained))
+	}
+	for i, d := range chained {
+		hashes := 0
+		for _, source := range d.Sources {
+			if _, ok := entryInfoHash(source); ok {
+				hashes++
+			}
+		}
+		if hashes != 1 {
+			t.Errorf("Wrong number of info hashes grouped for %d: %d", i, hashes)
+		}
+	}
+
+	// the result does not depend on the order of the entries
+	reversed := make([]Entry, len(entries))
+	for i := range entries {
+		reversed[len(entries)-1-i] = entries[i]
+	}
+	for i, d := range Deduplicate(reversed) {
+		if d.Entry.Meta.GUID != duplicates[i].Entry.Meta.GUID || len(d.Sources) != len(duplicates[i].Sources) {
+			t.Errorf("Different duplicates %d for reversed entries", i)
+		}
+	}
+}
+
+func TestDeduplicateOrder(t *testing.T) {
+	entries := entriesFromSample(t, "samples/torznab/torznab_tpb.xml", contentAuto)
+	entries = append(entries, entriesFromSample(t, "samples/torznab/torznab_hdaccess_net.xml", contentAuto)...)
+	// the same torrents from another indexer, without info hashes
+	for _, entry := range entriesFromSample(t, "samples/torznab/torznab_tpb.xml", contentAuto) {
+		entry.File = new(Torrent)
+		u, _ := url.Parse("https://example.com/" + url.PathEscape(entry.Release.Name))
+		entry.File.setURL(u)
+		entries = append(entries, entry)
+	}
+
+	describe := func(duplicates []Duplicates) string {
+		var description string
+		for _, d := range duplicates {
+			for _, source := range d.Sources {
+				description += fmt.Sprintf("%s %s|", source.Release.Name, entryDownloadURL(source))
+			}
+			description += "\n"
+		}
+		return description
+	}
+	expected := describe(Deduplicate(entries))
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		shuffled := make([]Entry, len(entries))
+		copy(shuffled, entries)
+		random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		if actual := describe(Deduplicate(shuffled)); actual != expected {
+			t.Fatalf("Different duplicates for shuffled entries:\n%s\nwanted\n%s", actual, expected)
+		}
+	}
+}
@@ -40,6 +40,8 @@ type Meta struct {
 	Authoring Authoring
 	// comments made on the entry
 	// Comments []Comment
+	// number of comments made on the entry
+	NumComments int64
 	// number of times the entry has been downloaded
 	Grabs int64
 	// optionally contains a link to a corresponding NFO file
@@ -48,6 +50,9 @@ type Meta struct {
 
 // Source describes information relating to the source of an entry
 type Source struct {
+	// name of the indexer the entry was obtained from, as configured in an
+	// Aggregator
+	Indexer string
 	// the endpoint called to get the entry
 	Endpoint *url.URL
 	// API key used to authenticate against the endpoint
@@ -85,6 +90,8 @@ type Authoring struct {
 	NNTPPoster string
 	// the NNTP groups for the NZB file
 	NNTPGroups []string
+	// the release group named by the indexer, if it named one
+	Team string
 }
 
 // FeedAuthor returns the author specified in the RSS feed the entry was
@@ -0,0 +1,95 @@
+package newznab
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/smquartz/errors"
+)
+
+// Fetch downloads the descriptor file of an entry, such as an NZB or torrent
+// file, and loads it into the entry's File. The type of the file is detected
+// from its contents and content type; if it differs from the type the feed
+// suggested, the entry's File is replaced by one of the detected type.
+func (c *Client) Fetch(ctx context.Context, entry *Entry) error {
+	if entry.File == nil || entry.File.URL() == nil {
+		return errors.Errorf("entry %s has no download URL", entry.Release.Name)
+	}
+	u := *entry.File.URL()
+	if u.Scheme == "magnet" {
+		return errors.Errorf("magnet URI for entry %s cannot be downloaded", entry.Release.Name)
+	}
+
+	// download links on the indexer itself may need authenticating
+	query := u.Query()
+	if u.Host == c.endpoint.Host && c.apiKey != "" && query.Get("apikey") == "" && query.Get("r") == "" {
+		query.Set("apikey", c.apiKey)
+		u.RawQuery = query.Encode()
+	}
+
+	raw, contentType, err := c.download(ctx, &u, "download")
+	if err != nil {
+		return err
+	}
+
+	file := entry.File
+	switch detectFileType(raw, contentType) {
+	case "nzb":
+		if _, ok := file.(*NZB); !ok {
+			file = convertFile(file, new(NZB))
+		}
+	case "torrent":
+		if _, ok := file.(*Torrent); !ok {
+			file = convertFile(file, new(Torrent))
+		}
+	default:
+		return errors.Errorf("unable to detect type of file downloaded for entry %s with content type %q", entry.Release.Name, contentType)
+	}
+
+	if err := file.load(raw); err != nil {
+		return err
+	}
+	entry.File = file
+	return nil
+}
+
+// detectFileType returns the type of a descriptor file, "nzb" or "torrent",
+// from its magic bytes or else its content type; "" if it cannot be detected
+func detectFileType(raw []byte, contentType string) string {
+	head := bytes.TrimSpace(bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")))
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	switch {
+	case bytes.HasPrefix(head, []byte("<")) && bytes.Contains(head, []byte("<nzb")):
+		return "nzb"
+	// torrent files are bencoded dictionaries, the first key of which is a
+	// length prefixed string
+	case len(head) > 2 && head[0] == 'd' && head[1] >= '0' && head[1] <= '9':
+		return "torrent"
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "nzb"):
+		return "nzb"
+	case strings.Contains(contentType, "bittorrent"):
+		return "torrent"
+	}
+	return ""
+}
+
+// convertFile copies the information reported by the feed about one File
+// onto another of a different type, and returns it
+func convertFile(from, to File) File {
+	to.setURL(from.URL())
+	to.setPassworded(from.Passworded())
+	if size := from.Size(); size > 0 {
+		to.setSize(size)
+	}
+	if numFiles := from.NumFiles(); numFiles > 0 {
+		to.setNumFiles(numFiles)
+	}
+	return to
+}
@@ -0,0 +1,76 @@
+package newznab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDetectFileType(t *testing.T) {
+	tests := []struct {
+		raw         string
+		contentType string
+		expected    string
+	}{
+		{"\xef\xbb\xbf<?xml version=\"1.0\"?>\n<!DOCTYPE nzb>\n<nzb></nzb>", "text/xml", "nzb"},
+		{"<nzb xmlns=\"http://www.newzbin.com/DTD/2003/nzb\"></nzb>", "", "nzb"},
+		{"d8:announce35:udp://tracker.example.com:80/announcee", "text/html", "torrent"},
+		{"binary", "application/x-bittorrent", "torrent"},
+		{"binary", "application/x-nzb; charset=utf-8", "nzb"},
+		{"<html></html>", "text/html", ""},
+	}
+	for _, test := range tests {
+		if actual := detectFileType([]byte(test.raw), test.contentType); actual != test.expected {
+			t.Errorf("Wrong type for %q: %q", test.raw, actual)
+		}
+	}
+}
+
+func TestClientFetch(t *testing.T) {
+	const raw = `<?xml version="1.0" encoding="UTF-8"?><nzb xmlns="http://www.newzbin.com/DTD/2003/nzb"></nzb>`
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/x-nzb")
+		w.Write([]byte(raw))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+
+	// the feed suggested a torrent, but the indexer serves an NZB
+	u, _ := url.Parse(server.URL + "/getnzb/abc.nzb?i=1")
+	entry := Entry{File: new(Torrent)}
+	entry.File.setURL(u)
+	entry.File.setSize(1183105773)
+	entry.File.setPassworded(true)
+	if err := client.Fetch(context.Background(), &entry); err != nil {
+		t.Fatalf("Error fetching: %v", err)
+	}
+
+	if query.Get("apikey") != "xxx" || query.Get("i") != "1" {
+		t.Errorf("Wrong query: %v", query)
+	}
+	n, ok := entry.File.(*NZB)
+	if !ok {
+		t.Fatalf("Wrong file type: %T", entry.File)
+	}
+	if b, err := n.Bytes(); err != nil || string(b) != raw {
+		t.Errorf("Wrong bytes: %s, %v", b, err)
+	}
+	if n.URL().String() != u.String() || !n.Passworded() || n.size != 1183105773 {
+		t.Errorf("Feed information not carried over: %v, %t, %d", n.URL(), n.Passworded(), n.size)
+	}
+
+	magnet, _ := url.Parse("magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a")
+	entry.File = new(Torrent)
+	entry.File.setURL(magnet)
+	if err := client.Fetch(context.Background(), &entry); err == nil {
+		t.Error("No error fetching magnet URI")
+	}
+}
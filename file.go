@@ -10,8 +10,12 @@ import (
 type File interface {
 	// returns the size of the file contents in bytes
 	Size() int64
+	// sets the size of the file contents in bytes, as reported by the feed
+	setSize(int64)
 	// returns the number of files in the entry
 	NumFiles() int
+	// sets the number of files in the entry, as reported by the feed
+	setNumFiles(int)
 	// returns whether the contents require a password to access
 	Passworded() bool
 	// sets whether the contents require a password to access
@@ -22,4 +26,12 @@ type File interface {
 	setURL(*url.URL)
 	// returns the raw bytes for descriptor file
 	Bytes() ([]byte, error)
+	// parses the raw bytes of the descriptor file into the File
+	load([]byte) error
 }
+
+// ensure that the File implementations satisfy the interface
+var (
+	_ File = (*NZB)(nil)
+	_ File = (*Torrent)(nil)
+)
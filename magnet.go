@@ -0,0 +1,109 @@
+package newznab
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/anacrolix/torrent/metainfo"
+	"github.com/smquartz/errors"
+)
+
+// Magnet describes a BitTorrent magnet URI
+type Magnet struct {
+	// info hash of the torrent (xt)
+	InfoHash metainfo.Hash
+	// display name of the torrent (dn)
+	DisplayName string
+	// tracker URLs for the torrent (tr)
+	Trackers []string
+	// total size of the files in the torrent in bytes (xl); 0 if not provided
+	Length int64
+}
+
+// btihPrefix prefixes the BitTorrent info hash in a magnet URI's exact topic
+const btihPrefix = "urn:btih:"
+
+// ParseMagnet parses a magnet URI, the info hash of which may be hex or base32
+// encoded
+func ParseMagnet(uri string) (Magnet, error) {
+	var m Magnet
+	u, err := url.Parse(uri)
+	if err != nil {
+		return m, errors.Wrapf(err, "unable to parse magnet URI %s", 1, uri)
+	}
+	if u.Scheme != "magnet" {
+		return m, errors.Errorf("%s is not a magnet URI", uri)
+	}
+	params, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return m, errors.Wrapf(err, "unable to parse parameters of magnet URI %s", 1, uri)
+	}
+
+	found := false
+	for _, xt := range params["xt"] {
+		if !strings.HasPrefix(strings.ToLower(xt), btihPrefix) {
+			continue
+		}
+		m.InfoHash, err = parseInfoHash(xt[len(btihPrefix):])
+		if err != nil {
+			return m, err
+		}
+		found = true
+		break
+	}
+	if !found {
+		return m, errors.Errorf("magnet URI %s has no BitTorrent info hash", uri)
+	}
+
+	m.DisplayName = params.Get("dn")
+	m.Trackers = params["tr"]
+	if xl := params.Get("xl"); xl != "" {
+		m.Length, err = strconv.ParseInt(xl, 10, 64)
+		if err != nil {
+			return m, errors.Wrapf(err, "invalid length %s in magnet URI", 1, xl)
+		}
+	}
+	return m, nil
+}
+
+// parseInfoHash parses a hex or base32 encoded info hash
+func parseInfoHash(encoded string) (metainfo.Hash, error) {
+	var hash metainfo.Hash
+	var decoded []byte
+	var err error
+	switch len(encoded) {
+	case hex.EncodedLen(len(hash)):
+		decoded, err = hex.DecodeString(encoded)
+	case base32.StdEncoding.EncodedLen(len(hash)):
+		decoded, err = base32.StdEncoding.DecodeString(strings.ToUpper(encoded))
+	default:
+		return hash, errors.Errorf("info hash %s has an invalid length", encoded)
+	}
+	if err != nil {
+		return hash, errors.Wrapf(err, "unable to decode info hash %s", 1, encoded)
+	}
+	copy(hash[:], decoded)
+	return hash, nil
+}
+
+// String returns the magnet URI
+func (m Magnet) String() string {
+	params := url.Values{}
+	if m.DisplayName != "" {
+		params.Set("dn", m.DisplayName)
+	}
+	if m.Length != 0 {
+		params.Set("xl", strconv.FormatInt(m.Length, 10))
+	}
+	for _, tracker := range m.Trackers {
+		params.Add("tr", tracker)
+	}
+	uri := "magnet:?xt=" + btihPrefix + hex.EncodeToString(m.InfoHash[:])
+	if len(params) > 0 {
+		uri += "&" + params.Encode()
+	}
+	return uri
+}
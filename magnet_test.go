@@ -0,0 +1,81 @@
+package newznab
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestParseMagnet(t *testing.T) {
+	magnet, err := ParseMagnet("magnet:?xt=urn:btih:9fb267cff5ae5603f07a347676ec3bf3e35f75e1&dn=Game+of+Thrones+S05E02+HDTV+x264-Xclusive+%5Beztv%5D&tr=udp:%2F%2Fopen.demonii.com:1337&tr=udp:%2F%2Ftracker.coppersurfer.tk:6969&xl=388895872")
+	if err != nil {
+		t.Fatalf("Error parsing magnet URI: %v", err)
+	}
+	if hex.EncodeToString(magnet.InfoHash[:]) != "9fb267cff5ae5603f07a347676ec3bf3e35f75e1" {
+		t.Errorf("Wrong info hash: %x", magnet.InfoHash)
+	}
+	if magnet.DisplayName != "Game of Thrones S05E02 HDTV x264-Xclusive [eztv]" {
+		t.Errorf("Wrong display name: %s", magnet.DisplayName)
+	}
+	if len(magnet.Trackers) != 2 || magnet.Trackers[0] != "udp://open.demonii.com:1337" {
+		t.Errorf("Wrong trackers: %v", magnet.Trackers)
+	}
+	if magnet.Length != 388895872 {
+		t.Errorf("Wrong length: %d", magnet.Length)
+	}
+
+	reparsed, err := ParseMagnet(magnet.String())
+	if err != nil {
+		t.Fatalf("Error parsing magnet URI %s: %v", magnet.String(), err)
+	}
+	if reparsed.InfoHash != magnet.InfoHash || reparsed.DisplayName != magnet.DisplayName || len(reparsed.Trackers) != 2 {
+		t.Errorf("Magnet URI did not round trip: %s", magnet.String())
+	}
+
+	magnet, err = ParseMagnet("magnet:?xt=urn:btih:VKRAHC7NT26KFQYS2HE4H2HAETIOWQKO&dn=Andy.McNabs.Tour.Of.Duty")
+	if err != nil {
+		t.Fatalf("Error parsing base32 magnet URI: %v", err)
+	}
+	if hex.EncodeToString(magnet.InfoHash[:]) != "aaa2038bed9ebca2c312d1c9c3e8e024d0eb414e" {
+		t.Errorf("Wrong base32 info hash: %x", magnet.InfoHash)
+	}
+
+	invalid := []string{
+		"http://example.com/file.torrent",
+		"magnet:?dn=no+hash",
+		"magnet:?xt=urn:btih:1234",
+		"magnet:?xt=urn:btih:9fb267cff5ae5603f07a347676ec3bf3e35f75e1&xl=big",
+	}
+	for _, uri := range invalid {
+		if _, err := ParseMagnet(uri); err == nil {
+			t.Errorf("Expected error for %s", uri)
+		}
+	}
+}
+
+func TestMagnetTorrent(t *testing.T) {
+	entries := entriesFromSample(t, "samples/torznab/torznab_tpb.xml", contentAuto)
+	torrent, ok := entries[0].File.(*Torrent)
+	if !ok {
+		t.Fatalf("Wrong file type: %T", entries[0].File)
+	}
+	if _, err := torrent.Bytes(); err != ErrNoMetaInfo {
+		t.Errorf("Wrong error for bytes: %v", err)
+	}
+	if len(torrent.Trackers()) != 4 {
+		t.Errorf("Wrong trackers: %v", torrent.Trackers())
+	}
+	hash := torrent.InfoHash()
+	if hex.EncodeToString(hash[:]) != "9fb267cff5ae5603f07a347676ec3bf3e35f75e1" {
+		t.Errorf("Wrong info hash: %x", hash)
+	}
+	if magnet, ok := torrent.Magnet(); !ok || magnet.DisplayName != "Game of Thrones S05E02 HDTV x264-Xclusive [eztv]" {
+		t.Errorf("Wrong magnet: %+v", magnet)
+	}
+
+	torrent = new(Torrent)
+	magnet, _ := ParseMagnet("magnet:?xt=urn:btih:9fb267cff5ae5603f07a347676ec3bf3e35f75e1&xl=1024")
+	torrent.magnet = &magnet
+	if torrent.Size() != 1024 {
+		t.Errorf("Wrong size from magnet length: %d", torrent.Size())
+	}
+}
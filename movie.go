@@ -26,3 +26,37 @@ func (m Movie) ReleaseYear() int {
 	year, _ := strconv.ParseInt(m.IMDBEntry.Year, 10, 64)
 	return int(year)
 }
+
+// attrs adds the newznab attributes describing the Movie Content to a list
+func (m Movie) attrs(a *rssAttrs) {
+	if imdbID, err := normaliseIMDBID(m.IMDBEntry.ImdbID); err == nil {
+		a.add("imdb", imdbID)
+	}
+	a.add("imdbtitle", m.IMDBEntry.Title)
+	a.add("imdbyear", m.IMDBEntry.Year)
+	m.TVMovie.attrs(a)
+	m.MovieMusicBook.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (m *Movie) setAttr(name, value string) bool {
+	switch name {
+	case "imdb":
+		if imdbID, err := normaliseIMDBID(value); err == nil {
+			m.IMDBEntry.ImdbID = imdbTitleID(imdbID)
+		}
+	case "imdbtitle":
+		m.IMDBEntry.Title = value
+	case "imdbyear":
+		m.IMDBEntry.Year = value
+	case "year":
+		// the IMDB year is more authoritative, if provided
+		if m.IMDBEntry.Year == "" {
+			m.IMDBEntry.Year = value
+		}
+	default:
+		return m.TVMovie.setAttr(name, value) || m.MovieMusicBook.setAttr(name, value)
+	}
+	return true
+}
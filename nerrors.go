@@ -1,6 +1,12 @@
 package newznab
 
-import "fmt"
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	nxml "github.com/smquartz/newznab/xml"
+)
 
 // NError describes an error defined by the newznab specification
 type NError struct {
@@ -86,7 +92,15 @@ func (n NErrorRange) withCode(code int) NErrorRange {
 // codeWithin returns whether an arbitrary error code is within the range
 // defined in a NErrorRange
 func (n NErrorRange) codeWithin(code int) bool {
-	return n.Code >= n.Min && n.Code <= n.Max
+	return code >= n.Min && code <= n.Max
+}
+
+// Is reports whether the target is the same error range, regardless of the
+// code of the actual unknown error; this allows errors.Is to match errors
+// against the ranges defined below
+func (n NErrorRange) Is(target error) bool {
+	t, ok := target.(NErrorRange)
+	return ok && t.Min == n.Min && t.Max == n.Max
 }
 
 // error ranges defined within the newznab spec
@@ -108,3 +122,37 @@ func nerrorRangeFromCode(code int) NErrorRange {
 	}
 	return ErrUnspecifiedOther
 }
+
+// nerrorFromCode takes the code of a newznab error, and returns the
+// corresponding NError, or NErrorRange if the code is not known
+func nerrorFromCode(code int) error {
+	if nerror, ok := nerrors[code]; ok {
+		return nerror
+	}
+	return nerrorRangeFromCode(code).withCode(code)
+}
+
+// nerrorFromResponse takes the body of an API response, and returns the error
+// it describes if it is a newznab error document, or nil otherwise
+func nerrorFromResponse(body []byte) error {
+	body = bytes.TrimPrefix(body, []byte("\xef\xbb\xbf"))
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "error" {
+			return nil
+		}
+		var e nxml.Error
+		if err := decoder.DecodeElement(&e, &start); err != nil {
+			return nil
+		}
+		return nerrorFromCode(e.Code)
+	}
+}
@@ -0,0 +1,41 @@
+package newznab
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestClientNError(t *testing.T) {
+	server := newTestServer("samples/newznab/unauthorized.xml", nil)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	_, err = client.Search(context.Background(), SearchQuery{})
+	if !errors.Is(err, ErrIncorrectUserCredentials) {
+		t.Errorf("Wrong error: %v", err)
+	}
+}
+
+func TestNErrorFromResponse(t *testing.T) {
+	err := nerrorFromResponse([]byte(`<?xml version="1.0" encoding="utf-8" ?><error code="910" description="API Disabled"/>`))
+	if !errors.Is(err, ErrAPIDisabled) {
+		t.Errorf("Wrong error: %v", err)
+	}
+
+	err = nerrorFromResponse([]byte(`<error code="250" description="Something odd"/>`))
+	if !errors.Is(err, ErrUnspecifiedAPICall) {
+		t.Errorf("Wrong error: %v", err)
+	}
+	var nerr NErrorRange
+	if !errors.As(err, &nerr) || nerr.Code != 250 {
+		t.Errorf("Wrong error code: %v", err)
+	}
+
+	if err := nerrorFromResponse([]byte(`<rss version="2.0"><channel></channel></rss>`)); err != nil {
+		t.Errorf("Unexpected error for feed: %v", err)
+	}
+}
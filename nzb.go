@@ -1,11 +1,17 @@
 package newznab
 
 import (
+	"encoding/xml"
 	"net/url"
 
+	"github.com/smquartz/errors"
 	"github.com/smquartz/nzb"
 )
 
+// ErrNoNZB is returned when the NZB file of an NZB is required, but has not
+// been loaded
+var ErrNoNZB = errors.New("NZB file has not been loaded")
+
 // NZB is a File implementation that describes an NZB file
 type NZB struct {
 	// embed the NZB representation
@@ -14,6 +20,12 @@ type NZB struct {
 	passworded bool
 	// url to download the raw NZB from
 	downloadURL *url.URL
+	// the raw NZB file, if it has been loaded
+	raw []byte
+	// size of the NZB contents in bytes, as reported by the feed
+	size int64
+	// number of files in the NZB, as reported by the feed
+	numFiles int
 }
 
 // URL returns a URL where the raw NZB file may be downloaded from
@@ -37,7 +49,67 @@ func (n *NZB) setPassworded(b bool) {
 	n.passworded = b
 }
 
-// NumFiles returns the number of files a NZB file contains
+// Size returns the total size of the files in the NZB in bytes, summed from
+// their segments if the NZB file has been loaded, or else as reported by the
+// feed. -1 is returned if the size is not known.
+func (n NZB) Size() int64 {
+	if len(n.Files) > 0 {
+		var size int64
+		for _, file := range n.Files {
+			for _, segment := range file.Segments {
+				size += int64(segment.Bytes)
+			}
+		}
+		return size
+	}
+	if n.size != 0 {
+		return n.size
+	}
+	return -1
+}
+
+// setSize sets the size of the contents of a NZB file in bytes, as reported by
+// the feed
+func (n *NZB) setSize(size int64) {
+	n.size = size
+}
+
+// NumFiles returns the number of files a NZB file contains, as reported by the
+// feed if the NZB file has not been loaded, or -1 if it is not known
 func (n NZB) NumFiles() int {
-	return len(n.Files)
+	if len(n.Files) > 0 {
+		return len(n.Files)
+	}
+	if n.numFiles != 0 {
+		return n.numFiles
+	}
+	return -1
+}
+
+// setNumFiles sets the number of files a NZB file contains, as reported by the
+// feed
+func (n *NZB) setNumFiles(numFiles int) {
+	n.numFiles = numFiles
+}
+
+// Bytes returns the raw bytes of the NZB file, or ErrNoNZB if it has not been
+// loaded
+func (n NZB) Bytes() ([]byte, error) {
+	if n.raw == nil {
+		return nil, ErrNoNZB
+	}
+	raw := make([]byte, len(n.raw))
+	copy(raw, n.raw)
+	return raw, nil
+}
+
+// load parses a raw NZB file into the NZB
+func (n *NZB) load(raw []byte) error {
+	var parsed nzb.NZB
+	if err := xml.Unmarshal(raw, &parsed); err != nil {
+		return errors.Wrapf(err, "unable to parse NZB", 1)
+	}
+	n.NZB = parsed
+	n.raw = raw
+	return nil
 }
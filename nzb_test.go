@@ -0,0 +1,40 @@
+package newznab
+
+import (
+	"testing"
+
+	"github.com/smquartz/nzb"
+)
+
+func TestNZBFile(t *testing.T) {
+	n := new(NZB)
+	if n.Size() != -1 || n.NumFiles() != -1 {
+		t.Errorf("Wrong size or number of files for empty NZB: %d, %d", n.Size(), n.NumFiles())
+	}
+	if _, err := n.Bytes(); err != ErrNoNZB {
+		t.Errorf("Wrong error for bytes: %v", err)
+	}
+
+	n.setSize(1183105773)
+	n.setNumFiles(2)
+	if n.Size() != 1183105773 || n.NumFiles() != 2 {
+		t.Errorf("Wrong size or number of files from feed: %d, %d", n.Size(), n.NumFiles())
+	}
+
+	n.Files = []nzb.File{
+		{Segments: []nzb.Segment{{Bytes: 100}, {Bytes: 50}}},
+		{Segments: []nzb.Segment{{Bytes: 25}}},
+		{Segments: []nzb.Segment{{Bytes: 10}}},
+	}
+	if n.Size() != 185 || n.NumFiles() != 3 {
+		t.Errorf("Wrong size or number of files from segments: %d, %d", n.Size(), n.NumFiles())
+	}
+	if _, err := n.Bytes(); err != ErrNoNZB {
+		t.Errorf("Wrong error for bytes of unloaded NZB: %v", err)
+	}
+
+	n.raw = []byte("<nzb></nzb>")
+	if raw, err := n.Bytes(); err != nil || string(raw) != "<nzb></nzb>" {
+		t.Errorf("Wrong bytes: %s, %v", raw, err)
+	}
+}
@@ -0,0 +1,176 @@
+package newznab
+
+import "context"
+
+// Pager iterates over every page of the results of a search, advancing the
+// offset until every result the indexer reports has been returned, or the
+// caller's Max is reached. It is used much like a bufio.Scanner:
+//
+//	pager := client.TVSearchPager(TVQuery{TVDBID: 108611})
+//	for pager.Next(ctx) {
+//		results := pager.Results()
+//		...
+//	}
+//	if err := pager.Err(); err != nil {
+//		...
+//	}
+type Pager struct {
+	// maximum number of entries to return across every page; unlimited if
+	// zero
+	Max int
+
+	// the client searches are executed with
+	client *Client
+	// executes the search for a single page
+	search func(ctx context.Context, limit, offset int) (Results, error)
+	// number of results requested per page; the indexer's default if zero
+	limit int
+	// offset of the next page
+	offset int
+	// whether the limit has been determined
+	started bool
+	// whether every page has been returned
+	done bool
+	// number of entries returned so far
+	returned int
+	// the current page
+	results Results
+	// the error that stopped iteration, if any
+	err error
+}
+
+// newPager returns a Pager that executes searches using the provided function,
+// starting at the provided limit and offset
+func (c *Client) newPager(limit, offset int, search func(ctx context.Context, limit, offset int) (Results, error)) *Pager {
+	return &Pager{client: c, search: search, limit: limit, offset: offset, results: Results{Total: -1}}
+}
+
+// SearchPager returns a Pager over every page of a generic search (t=search).
+// The query's Limit is the size of each page, and its Offset the start of the
+// first.
+func (c *Client) SearchPager(q SearchQuery) *Pager {
+	return c.newPager(q.Limit, q.Offset, func(ctx context.Context, limit, offset int) (Results, error) {
+		q.Limit, q.Offset = limit, offset
+		return c.Search(ctx, q)
+	})
+}
+
+// TVSearchPager returns a Pager over every page of a TV search (t=tvsearch).
+// The query's Limit is the size of each page, and its Offset the start of the
+// first.
+func (c *Client) TVSearchPager(q TVQuery) *Pager {
+	return c.newPager(q.Limit, q.Offset, func(ctx context.Context, limit, offset int) (Results, error) {
+		q.Limit, q.Offset = limit, offset
+		return c.TVSearch(ctx, q)
+	})
+}
+
+// MovieSearchPager returns a Pager over every page of a movie search
+// (t=movie). The query's Limit is the size of each page, and its Offset the
+// start of the first.
+func (c *Client) MovieSearchPager(q MovieQuery) *Pager {
+	return c.newPager(q.Limit, q.Offset, func(ctx context.Context, limit, offset int) (Results, error) {
+		q.Limit, q.Offset = limit, offset
+		return c.MovieSearch(ctx, q)
+	})
+}
+
+// MusicSearchPager returns a Pager over every page of a music search
+// (t=music). The query's Limit is the size of each page, and its Offset the
+// start of the first.
+func (c *Client) MusicSearchPager(q MusicQuery) *Pager {
+	return c.newPager(q.Limit, q.Offset, func(ctx context.Context, limit, offset int) (Results, error) {
+		q.Limit, q.Offset = limit, offset
+		return c.MusicSearch(ctx, q)
+	})
+}
+
+// BookSearchPager returns a Pager over every page of a book search (t=book).
+// The query's Limit is the size of each page, and its Offset the start of the
+// first.
+func (c *Client) BookSearchPager(q BookQuery) *Pager {
+	return c.newPager(q.Limit, q.Offset, func(ctx context.Context, limit, offset int) (Results, error) {
+		q.Limit, q.Offset = limit, offset
+		return c.BookSearch(ctx, q)
+	})
+}
+
+// Next retrieves the next page of results, which is then available through
+// Results. It returns false once every page has been retrieved, the Pager's
+// Max is reached, or an error occurs, including the context being cancelled.
+func (p *Pager) Next(ctx context.Context) bool {
+	if p.done || p.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if !p.started {
+		p.started = true
+		// without a page size, request as many results as the indexer permits
+		if p.limit == 0 {
+			if caps, err := p.client.Capabilities(ctx); err == nil {
+				p.limit = caps.Limits.Max
+			}
+		}
+	}
+
+	limit := p.limit
+	if p.Max > 0 {
+		remaining := p.Max - p.returned
+		if remaining <= 0 {
+			p.done = true
+			return false
+		}
+		if limit == 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+
+	results, err := p.search(ctx, limit, p.offset)
+	if err != nil {
+		p.err = err
+		return false
+	}
+	if results.items() == 0 {
+		p.done = true
+		return false
+	}
+
+	if p.Max > 0 && p.returned+len(results.Entries) > p.Max {
+		results.Entries = results.Entries[:p.Max-p.returned]
+	}
+	p.returned += len(results.Entries)
+	p.results = results
+
+	// the indexer may return fewer results than requested, so the next page
+	// starts after the last item actually returned
+	p.offset += results.items()
+	if results.Total >= 0 && p.offset >= results.Total {
+		p.done = true
+	}
+	// indexers may silently return smaller pages than requested, so a short
+	// page only marks the end if the total is unknown
+	if results.Total < 0 && p.limit > 0 && results.items() < p.limit {
+		p.done = true
+	}
+	return true
+}
+
+// Results returns the current page of results
+func (p *Pager) Results() Results {
+	return p.results
+}
+
+// Total returns the total number of results of the search as reported by the
+// indexer, or -1 if it is unknown
+func (p *Pager) Total() int {
+	return p.results.Total
+}
+
+// Err returns the error that stopped iteration, if any
+func (p *Pager) Err() error {
+	return p.err
+}
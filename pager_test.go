@@ -0,0 +1,109 @@
+package newznab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPager(t *testing.T) {
+	var requests []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, map[string]string{"limit": r.URL.Query().Get("limit"), "offset": r.URL.Query().Get("offset")})
+		http.ServeFile(w, r, "samples/newznab/newznab_nzb_su.xml")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	pager := client.SearchPager(SearchQuery{Query: "white collar", Limit: 100})
+	pager.Max = 250
+	var entries []Entry
+	for pager.Next(context.Background()) {
+		entries = append(entries, pager.Results().Entries...)
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatalf("Error paging: %v", err)
+	}
+
+	if len(entries) != 250 {
+		t.Errorf("Wrong number of entries: %d", len(entries))
+	}
+	if pager.Total() != 10000 {
+		t.Errorf("Wrong total: %d", pager.Total())
+	}
+	expected := []map[string]string{
+		{"limit": "100", "offset": ""},
+		{"limit": "100", "offset": "100"},
+		{"limit": "50", "offset": "200"},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("Wrong number of requests: %d", len(requests))
+	}
+	for i := range expected {
+		for k, v := range expected[i] {
+			if requests[i][k] != v {
+				t.Errorf("Wrong %s parameter of request %d: %s", k, i, requests[i][k])
+			}
+		}
+	}
+}
+
+func TestPagerShortPages(t *testing.T) {
+	var limits []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		// the indexer returns at most 100 results, whatever the limit
+		http.ServeFile(w, r, "samples/newznab/newznab_nzb_su.xml")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	pager := client.SearchPager(SearchQuery{Query: "white collar", Limit: 500})
+	pager.Max = 250
+	var entries []Entry
+	for pager.Next(context.Background()) {
+		entries = append(entries, pager.Results().Entries...)
+	}
+	if err := pager.Err(); err != nil {
+		t.Fatalf("Error paging: %v", err)
+	}
+	if len(entries) != 250 || len(limits) != 3 {
+		t.Errorf("Wrong number of entries or requests: %d, %v", len(entries), limits)
+	}
+}
+
+func TestPagerTotal(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.ServeFile(w, r, "samples/newznab/newznab_music.xml")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	pager := client.MusicSearchPager(MusicQuery{Artist: "Pink Floyd", Limit: 10})
+	pages := 0
+	for pager.Next(context.Background()) {
+		pages++
+	}
+	if pager.Err() != nil || pages != 1 || requests != 1 {
+		t.Errorf("Wrong pages: %d pages, %d requests, %v", pages, requests, pager.Err())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pager = client.MusicSearchPager(MusicQuery{Artist: "Pink Floyd"})
+	if pager.Next(ctx) || pager.Err() != context.Canceled {
+		t.Errorf("Wrong error for cancelled context: %v", pager.Err())
+	}
+}
@@ -1,14 +1,17 @@
 package newznab
 
 import (
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
 	uuid "github.com/satori/go.uuid"
 	"github.com/smquartz/errors"
+	"github.com/smquartz/newznab/release"
 )
 
 // parseDate attempts to parse a date string
@@ -24,55 +27,100 @@ func parseDate(date string) (time.Time, error) {
 	return parsedTime, errors.Errorf("failed to parse date %s as one of %s", date, strings.Join(formats, ", "))
 }
 
-// entriesFromFeed takes a gofeed.Feed and returns a parsed []Entry slice
-func entriesFromFeed(feed gofeed.Feed) ([]Entry, error) {
-	var entries []Entry
-	for _, item := range feed.Items {
+// resultsFromFeed takes a gofeed.Feed and returns the Entries parsed from its
+// items, the Content of which is of the provided kind. Items that cannot be
+// parsed are described in the Results' Errors, unless strict is set, in which
+// case the first such ParseError is returned.
+func resultsFromFeed(feed gofeed.Feed, kind contentKind, strict bool) (Results, error) {
+	results := Results{Total: -1}
+	for _, namespace := range attrNamespaces {
+		for _, response := range feed.Extensions[namespace]["response"] {
+			if offset, err := strconv.Atoi(response.Attrs["offset"]); err == nil {
+				results.Offset = offset
+			}
+			if total, err := strconv.Atoi(response.Attrs["total"]); err == nil {
+				results.Total = total
+			}
+		}
+	}
+	for i, item := range feed.Items {
 		if item == nil {
 			continue
 		}
-		entry, err := entryFromItem(feed, *item)
+		entry, err := entryFromItem(feed, *item, kind)
 		if err != nil {
+			parseErr := ParseError{Index: i, GUID: item.GUID, Cause: err}
+			if fieldErr, ok := err.(fieldError); ok {
+				parseErr.Field, parseErr.Cause = fieldErr.field, fieldErr.cause
+			}
+			if strict {
+				return Results{}, parseErr
+			}
+			results.Errors = append(results.Errors, parseErr)
 			continue
 		}
-		entries = append(entries, entry)
+		results.Entries = append(results.Entries, entry)
 	}
-	return entries, nil
+	return results, nil
+}
+
+// fieldError describes an error parsing a particular field of an item
+type fieldError struct {
+	field string
+	cause error
 }
 
-// entryFromItem takes a gofeed.Item and returns a parsed Entry struct
-func entryFromItem(feed gofeed.Feed, item gofeed.Item) (Entry, error) {
+// Error implements the error interface for the fieldError type
+func (f fieldError) Error() string {
+	return fmt.Sprintf("unable to parse %s: %v", f.field, f.cause)
+}
+
+// entryFromItem takes a gofeed.Item and returns a parsed Entry struct, the
+// Content of which is of the provided kind
+func entryFromItem(feed gofeed.Feed, item gofeed.Item, kind contentKind) (Entry, error) {
 	var newEntry Entry
-	// var err error
-	// var year int
-	/*
-		var hasNFO bool
-		var tvMovie TVMovie
-		var tvMovieMusic TVMovieMusic
-		var musicBook MusicBook
-		var tvMovieMusicBook TVMovieMusicBook
-		var movieMusicBook TVMovieMusicBook
-	*/
+	content := newContentBuilder(kind)
 
 	newEntry.Meta.Source.Feed = feed
 	newEntry.Meta.Source.Item = item
-	newEntry.Meta.Dates.Published = *item.PublishedParsed
-	newEntry.Release.Name = item.Title
-	if strings.Contains(item.Enclosures[0].URL, ".nzb") {
-		newEntry.File = new(NZB)
-	} else if strings.Contains(item.Enclosures[0].URL, ".torrent") || strings.Contains(item.Enclosures[0].URL, "magnet:?") {
-		newEntry.File = new(Torrent)
+	if item.PublishedParsed != nil {
+		newEntry.Meta.Dates.Published = *item.PublishedParsed
+	} else {
+		published, err := parseDate(item.Published)
+		if err != nil {
+			return newEntry, fieldError{field: "pubDate", cause: errors.Errorf("missing or invalid date %q", item.Published)}
+		}
+		newEntry.Meta.Dates.Published = published
+	}
+	if item.UpdatedParsed != nil {
+		newEntry.Meta.Dates.Updated = *item.UpdatedParsed
+	}
+	newEntry.Release = release.Parse(item.Title)
+	newEntry.Meta.Categorisation.RSSCategories = item.Categories
+
+	file, err := fileFromItem(item)
+	if err != nil {
+		return newEntry, fieldError{field: "enclosure", cause: err}
+	}
+	newEntry.File = file
+	if len(item.Enclosures) > 0 && item.Enclosures[0] != nil {
+		// the size attribute takes precedence, if present
+		if length, err := strconv.ParseInt(item.Enclosures[0].Length, 10, 64); err == nil && length > 0 {
+			file.setSize(length)
+		}
 	}
-	u, _ := url.Parse(item.Enclosures[0].URL)
-	newEntry.File.setURL(u)
 
-	for _, attr := range item.Extensions["newznab"]["attr"] {
+	for _, attr := range itemAttrs(item) {
 		name := attr.Attrs["name"]
 		value := attr.Attrs["value"]
 		intValue, intErr := strconv.ParseInt(value, 10, 64)
 
 		switch name {
-		// case "size":
+		case "size":
+			if intErr != nil {
+				continue
+			}
+			newEntry.File.setSize(intValue)
 		case "category":
 			if intErr != nil {
 				continue
@@ -82,70 +130,130 @@ func entryFromItem(feed gofeed.Feed, item gofeed.Item) (Entry, error) {
 		case "guid":
 			guid, _ := uuid.FromString(value)
 			newEntry.Meta.GUID = guid
-		// case "files":
+		case "files":
+			if intErr != nil {
+				continue
+			}
+			newEntry.File.setNumFiles(int(intValue))
 		case "poster":
 			newEntry.Meta.Authoring.NNTPPoster = value
 		case "group":
 			newEntry.Meta.Authoring.NNTPGroups = append(newEntry.Meta.Authoring.NNTPGroups, value)
 		case "team":
-			newEntry.Release.Group = value
+			newEntry.Meta.Authoring.Team = value
 		case "grabs":
 			newEntry.Meta.Grabs = intValue
+			if torrent, ok := newEntry.File.(*Torrent); ok {
+				torrent.setAttr(name, value)
+			}
 		case "password":
 			passworded, _ := strconv.ParseBool(value)
 			newEntry.File.setPassworded(passworded)
 		case "comments":
-			// todo
+			if intErr != nil {
+				continue
+			}
+			newEntry.Meta.NumComments = intValue
 		case "usenetdate":
 			newEntry.Meta.Dates.PublishedUsenet, _ = parseDate(value)
 		case "info":
 			newEntry.Meta.NFO, _ = url.Parse(value)
-		// case "year":
-		// 	year = int(intValue)
-		case "season":
-			if newEntry.Content == nil {
-				newEntry.Content = TV{}
-			}
-			tv, ok := newEntry.Content.(TV)
-			if !ok {
-				continue
-			}
-			tv.Season = int(intValue)
-		case "episode":
-			if newEntry.Content == nil {
-				newEntry.Content = TV{}
-			}
-			tv, ok := newEntry.Content.(TV)
-			if !ok {
-				continue
-			}
-			if intErr != nil {
-				intValue, intErr = strconv.ParseInt(strings.Split(value, "/")[0], 10, 64)
-			}
-			if intErr != nil {
-				continue
-			}
-			tv.Episode = int(intValue)
-		case "rageid":
-			if newEntry.Content == nil {
-				newEntry.Content = TV{}
-			}
-			tv, ok := newEntry.Content.(TV)
-			if !ok {
-				continue
-			}
-			tv.TVRageID = intValue
-		case "tvtitle":
-			if newEntry.Content == nil {
-				newEntry.Content = TV{}
-			}
-			tv, ok := newEntry.Content.(TV)
-			if !ok {
+		default:
+			if torrent, ok := newEntry.File.(*Torrent); ok && torrent.setAttr(name, value) {
 				continue
 			}
-			tv.TVRageTitle = value
+			content.setAttr(name, value)
 		}
 	}
+	newEntry.Content = content.content(newEntry.Meta.Categorisation.Categories)
+
+	// anime is named differently from scene releases, and is not always
+	// recognisable by its name alone
+	for _, category := range newEntry.Meta.Categorisation.Categories {
+		if category == CategoryTVAnime && !newEntry.Release.Anime {
+			newEntry.Release = release.ParseAnime(item.Title)
+		}
+	}
+	if newEntry.Meta.Authoring.Team != "" {
+		newEntry.Release.Group = newEntry.Meta.Authoring.Team
+	}
 
 	return newEntry, nil
 }
+
+// attrNamespaces are the prefixes of the namespaces from which attributes are
+// read; torznab feeds use the same attributes as newznab, with extras for
+// torrents
+var attrNamespaces = []string{"newznab", "torznab"}
+
+// itemAttrs returns the newznab and torznab attributes of a gofeed.Item
+func itemAttrs(item gofeed.Item) []ext.Extension {
+	var attrs []ext.Extension
+	for _, namespace := range attrNamespaces {
+		attrs = append(attrs, item.Extensions[namespace]["attr"]...)
+	}
+	return attrs
+}
+
+// fileFromItem returns a File describing the enclosure of a gofeed.Item, or its
+// link if it has no enclosure
+func fileFromItem(item gofeed.Item) (File, error) {
+	var rawURL, fileType string
+	if len(item.Enclosures) > 0 && item.Enclosures[0] != nil {
+		rawURL, fileType = item.Enclosures[0].URL, item.Enclosures[0].Type
+	} else {
+		rawURL = item.Link
+	}
+	if rawURL == "" {
+		return nil, errors.Errorf("missing download URL")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid download URL %s", 1, rawURL)
+	}
+	if u.Scheme != "magnet" && !u.IsAbs() {
+		return nil, errors.Errorf("download URL %s is not absolute", rawURL)
+	}
+
+	var file File
+	switch {
+	case strings.Contains(fileType, "nzb"):
+		file = new(NZB)
+	case strings.Contains(fileType, "bittorrent"), u.Scheme == "magnet":
+		file = new(Torrent)
+	case strings.Contains(rawURL, ".nzb"):
+		file = new(NZB)
+	case strings.Contains(rawURL, ".torrent"):
+		file = new(Torrent)
+	default:
+		return nil, errors.Errorf("unknown file type %q for download URL %s", fileType, rawURL)
+	}
+	file.setURL(u)
+	return file, nil
+}
+
+// splitList splits a list attribute value, such as a track listing, into its
+// trimmed, non-empty elements
+func splitList(value, sep string) []string {
+	var list []string
+	for _, element := range strings.Split(value, sep) {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// parsePrefixedInt parses an integer attribute value that may be prefixed, such
+// as S01 for a season
+func parsePrefixedInt(value, prefix string) (int, error) {
+	value = strings.TrimSpace(value)
+	if len(value) >= len(prefix) && strings.EqualFold(value[:len(prefix)], prefix) {
+		value = value[len(prefix):]
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to parse %s as an integer", 1, value)
+	}
+	return i, nil
+}
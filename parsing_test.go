@@ -1,11 +1,14 @@
 package newznab
 
 import (
+	"encoding/hex"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mmcdole/gofeed"
 	uuid "github.com/satori/go.uuid"
+	"github.com/smquartz/newznab/release"
 )
 
 func TestEntriesFromFeed(t *testing.T) {
@@ -20,10 +23,11 @@ func TestEntriesFromFeed(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error parsing test XML: %v", err)
 	}
-	entries, err := entriesFromFeed(*feed)
+	results, err := resultsFromFeed(*feed, contentAuto, true)
 	if err != nil {
 		t.Errorf("Error parsing test feed: %v", err)
 	}
+	entries := results.Entries
 
 	if len(entries) != 100 {
 		t.Errorf("Wrong number of entries: %d", len(entries))
@@ -37,6 +41,9 @@ func TestEntriesFromFeed(t *testing.T) {
 	if testEntry.Release.Name != "White.Collar.S03E05.720p.HDTV.X264-DIMENSION" {
 		t.Errorf("Wrong release name: %s", testEntry.Release.Name)
 	}
+	if testEntry.Release.Title != "White Collar" || testEntry.Release.Season != 3 || testEntry.Release.Resolution != release.Resolution720p || testEntry.Release.Group != "DIMENSION" {
+		t.Errorf("Wrong parsed release: %+v", testEntry.Release)
+	}
 	if testEntry.Meta.Categorisation.Categories[0] != CategoryFromCode(5000) {
 		t.Errorf("Wrong category 0: %v", testEntry.Meta.Categorisation.Categories[0])
 	}
@@ -55,3 +62,272 @@ func TestEntriesFromFeed(t *testing.T) {
 		t.Errorf("File field is empty")
 	}
 }
+
+// entriesFromSample parses the feed at the provided path into entries with
+// Content of the provided kind
+func entriesFromSample(t *testing.T, path string, kind contentKind) []Entry {
+	testFile, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Error opening test XML: %v", err)
+	}
+	defer testFile.Close()
+
+	feed, err := gofeed.NewParser().Parse(testFile)
+	if err != nil {
+		t.Fatalf("Error parsing test XML: %v", err)
+	}
+	results, err := resultsFromFeed(*feed, kind, true)
+	if err != nil {
+		t.Fatalf("Error parsing test feed: %v", err)
+	}
+	return results.Entries
+}
+
+func TestMusicEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/newznab/newznab_music.xml", contentMusic)
+	if len(entries) != 1 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	music, ok := entries[0].Content.(Music)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entries[0].Content)
+	}
+	if music.Artist != "Daft Punk" || music.Album != "Random Access Memories" {
+		t.Errorf("Wrong artist or album: %s, %s", music.Artist, music.Album)
+	}
+	if music.Publisher != "Columbia" || music.Genre != "Electronic" || music.ReleaseYear() != 2013 {
+		t.Errorf("Wrong publisher, genre or year: %s, %s, %d", music.Publisher, music.Genre, music.ReleaseYear())
+	}
+	if len(music.Tracks) != 3 || music.Tracks[1] != "The Game of Love" {
+		t.Errorf("Wrong tracks: %v", music.Tracks)
+	}
+}
+
+func TestBookEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/newznab/newznab_book.xml", contentBook)
+	if len(entries) != 1 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	book, ok := entries[0].Content.(Book)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entries[0].Content)
+	}
+	if book.Title() != "Dune" || book.Author != "Frank Herbert" || book.Publisher != "Chilton Books" {
+		t.Errorf("Wrong title, author or publisher: %s, %s, %s", book.Title(), book.Author, book.Publisher)
+	}
+	if book.ReleaseYear() != 1965 || book.Pages != 412 {
+		t.Errorf("Wrong year or pages: %d, %d", book.ReleaseYear(), book.Pages)
+	}
+}
+
+func TestMovieEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/newznab/newznab_movie.xml", contentMovie)
+	if len(entries) != 1 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Meta.NumComments != 4 || entry.Meta.Grabs != 1520 {
+		t.Errorf("Wrong comments or grabs: %d, %d", entry.Meta.NumComments, entry.Meta.Grabs)
+	}
+	if nzb, ok := entry.File.(*NZB); !ok || nzb.size != 8547321234 || nzb.NumFiles() != 97 {
+		t.Errorf("Wrong file: %+v", entry.File)
+	}
+
+	movie, ok := entry.Content.(Movie)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entry.Content)
+	}
+	if movie.IMDBEntry.ImdbID != "tt0133093" || movie.Title() != "The Matrix" || movie.ReleaseYear() != 1999 {
+		t.Errorf("Wrong IMDB entry: %+v", movie.IMDBEntry)
+	}
+	if movie.Genre != "Action, Sci-Fi" || movie.VideoCodec != "AVC" || movie.AudioCodec != "DTS" || movie.Resolution != "1920x800" {
+		t.Errorf("Wrong genre, codecs or resolution: %s, %s, %s, %s", movie.Genre, movie.VideoCodec, movie.AudioCodec, movie.Resolution)
+	}
+	if movie.Framerate != 23.976 {
+		t.Errorf("Wrong framerate: %f", movie.Framerate)
+	}
+	if len(movie.Languages) != 2 || movie.Languages[1] != "French" || len(movie.Subtitles) != 2 || movie.Subtitles[1] != "Spanish" {
+		t.Errorf("Wrong languages or subtitles: %v, %v", movie.Languages, movie.Subtitles)
+	}
+	if movie.CoverImage.String() != "http://example.com/covers/movies/133093-cover.jpg" {
+		t.Errorf("Wrong cover image: %s", movie.CoverImage.String())
+	}
+	if movie.BackdropCoverImage.String() != "http://example.com/covers/movies/133093-backdrop.jpg" {
+		t.Errorf("Wrong backdrop cover image: %s", movie.BackdropCoverImage.String())
+	}
+	if movie.ReviewScore != 8.7 {
+		t.Errorf("Wrong review score: %f", movie.ReviewScore)
+	}
+}
+
+func TestTVEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/newznab/newznab_tv_attrs.xml", contentAuto)
+
+	expected := []struct {
+		season  int
+		episode int
+	}{{3, 5}, {3, 4}, {3, 3}}
+	for i, e := range expected {
+		tv, ok := entries[i].Content.(TV)
+		if !ok {
+			t.Fatalf("Wrong content type for entry %d: %T", i, entries[i].Content)
+		}
+		if tv.Season != e.season || tv.Episode != e.episode {
+			t.Errorf("Wrong season or episode for entry %d: S%02dE%02d", i, tv.Season, tv.Episode)
+		}
+		if tv.TVRageID != 20720 || tv.Title() != "White Collar" {
+			t.Errorf("Wrong TVRage ID or title for entry %d: %d, %s", i, tv.TVRageID, tv.Title())
+		}
+	}
+
+	// entries without TV attributes are still TV by category
+	if _, ok := entries[3].Content.(TV); !ok {
+		t.Errorf("Wrong content type for entry 3: %T", entries[3].Content)
+	}
+}
+
+func TestContentBuilder(t *testing.T) {
+	// shared attributes preceding specific ones must not be lost
+	builder := newContentBuilder(contentAuto)
+	builder.setAttr("imdb", "0133093")
+	builder.setAttr("genre", "Action")
+	builder.setAttr("imdbtitle", "The Matrix")
+	movie, ok := builder.content(nil).(Movie)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", builder.content(nil))
+	}
+	if movie.IMDBEntry.ImdbID != "tt0133093" || movie.Genre != "Action" || movie.Title() != "The Matrix" {
+		t.Errorf("Wrong movie: %+v", movie)
+	}
+
+	builder = newContentBuilder(contentAuto)
+	builder.setAttr("genre", "Action")
+	if content := builder.content(nil); content != nil {
+		t.Errorf("Content inferred without specific attributes or categories: %T", content)
+	}
+	if _, ok := builder.content([]Category{CategoryMoviesHD}).(Movie); !ok {
+		t.Errorf("Content not inferred from categories")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		path  string
+		field string
+	}{
+		{"samples/TorrentRss/invalid/TorrentDay_NoPubDate.xml", "pubDate"},
+		{"samples/TorrentRss/invalid/ImmortalSeed_InvalidDownloadUrl.xml", "enclosure"},
+	}
+	for _, test := range tests {
+		testFile, err := os.Open(test.path)
+		if err != nil {
+			t.Fatalf("Error opening test XML: %v", err)
+		}
+		feed, err := gofeed.NewParser().Parse(testFile)
+		testFile.Close()
+		if err != nil {
+			t.Fatalf("Error parsing test XML: %v", err)
+		}
+
+		results, err := resultsFromFeed(*feed, contentAuto, false)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", test.path, err)
+		}
+		if len(results.Entries) != 0 || len(results.Errors) != 1 {
+			t.Fatalf("Wrong number of entries or errors for %s: %d, %d", test.path, len(results.Entries), len(results.Errors))
+		}
+		if results.Errors[0].Field != test.field || results.Errors[0].Index != 0 {
+			t.Errorf("Wrong parse error for %s: %v", test.path, results.Errors[0])
+		}
+
+		_, err = resultsFromFeed(*feed, contentAuto, true)
+		if _, ok := err.(ParseError); !ok {
+			t.Errorf("Wrong strict error for %s: %v", test.path, err)
+		}
+	}
+}
+
+func TestEntryPublishedFallback(t *testing.T) {
+	item := gofeed.Item{Title: "White.Collar.S03E05.720p.HDTV.X264-DIMENSION", Link: "https://example.com/get/1.nzb", Published: "2015-04-12T00:14:00Z"}
+	entry, err := entryFromItem(gofeed.Feed{}, item, contentAuto)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !entry.Meta.Dates.Published.Equal(time.Date(2015, 4, 12, 0, 14, 0, 0, time.UTC)) {
+		t.Errorf("Wrong published date: %v", entry.Meta.Dates.Published)
+	}
+
+	item.Published = "yesterday"
+	if _, err := entryFromItem(gofeed.Feed{}, item, contentAuto); err == nil {
+		t.Errorf("No error for invalid published date")
+	}
+}
+
+func TestTorznabEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/torznab/torznab_hdaccess_net.xml", contentAuto)
+	if len(entries) != 5 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	torrent, ok := entries[0].File.(*Torrent)
+	if !ok {
+		t.Fatalf("Wrong file type: %T", entries[0].File)
+	}
+	if torrent.Seeders() != 7 || torrent.Peers() != 7 || torrent.Leechers() != 0 {
+		t.Errorf("Wrong seeders, peers or leechers: %d, %d, %d", torrent.Seeders(), torrent.Peers(), torrent.Leechers())
+	}
+	if hex.EncodeToString(torrent.infoHash[:]) != "63e07ff523710ca268567dad344ce1e0e6b7e8a3" {
+		t.Errorf("Wrong info hash: %x", torrent.infoHash)
+	}
+	if torrent.MinimumRatio() != 1 || torrent.MinimumSeedTime() != 48*time.Hour {
+		t.Errorf("Wrong minimum ratio or seed time: %f, %v", torrent.MinimumRatio(), torrent.MinimumSeedTime())
+	}
+	if torrent.Freeleech() || torrent.UploadVolumeFactor() != 1 {
+		t.Errorf("Wrong volume factors: %f, %f", torrent.DownloadVolumeFactor(), torrent.UploadVolumeFactor())
+	}
+	if torrent.Size() != 2538463390 {
+		t.Errorf("Wrong size: %d", torrent.Size())
+	}
+	tv, ok := entries[0].Content.(TV)
+	if !ok {
+		t.Fatalf("Wrong content type: %T", entries[0].Content)
+	}
+	if tv.TVRageID != 37780 || tv.TVDBID != 273181 || tv.IMDBID != "3032476" {
+		t.Errorf("Wrong series IDs: %d, %d, %s", tv.TVRageID, tv.TVDBID, tv.IMDBID)
+	}
+
+	entries = entriesFromSample(t, "samples/torznab/torznab_tpb.xml", contentAuto)
+	torrent, ok = entries[0].File.(*Torrent)
+	if !ok {
+		t.Fatalf("Wrong file type: %T", entries[0].File)
+	}
+	if torrent.Seeders() != 34128 || torrent.Leechers() != 36724-34128 {
+		t.Errorf("Wrong seeders or leechers: %d, %d", torrent.Seeders(), torrent.Leechers())
+	}
+	if u := torrent.MagnetURI(); u == nil || u.Scheme != "magnet" {
+		t.Errorf("Wrong magnet URI: %v", u)
+	}
+	if torrent.Size() != 388895872 {
+		t.Errorf("Wrong size: %d", torrent.Size())
+	}
+}
+
+func TestTorrentVolumeFactors(t *testing.T) {
+	torrent := new(Torrent)
+	torrent.setAttr("downloadvolumefactor", "0")
+	torrent.setAttr("uploadvolumefactor", "2")
+	torrent.setAttr("grabs", "12")
+	if !torrent.Freeleech() || torrent.UploadVolumeFactor() != 2 || torrent.Grabs() != 12 {
+		t.Errorf("Wrong volume factors or grabs: %f, %f, %d", torrent.DownloadVolumeFactor(), torrent.UploadVolumeFactor(), torrent.Grabs())
+	}
+}
+
+func TestAnimeEntries(t *testing.T) {
+	entries := entriesFromSample(t, "samples/torznab/torznab_animetosho.xml", contentAuto)
+	if len(entries) != 2 {
+		t.Fatalf("Wrong number of entries: %d", len(entries))
+	}
+	r := entries[0].Release
+	if !r.Anime || r.Title != "Frame Arms Girl" || r.Group != "finFAGs" || len(r.AbsoluteEpisodes) != 1 || r.AbsoluteEpisodes[0] != 7 || r.CRC32 != "1262B6F7" {
+		t.Errorf("Wrong parsed release: %+v", r)
+	}
+}
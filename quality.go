@@ -0,0 +1,210 @@
+package newznab
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/smquartz/errors"
+	"github.com/smquartz/newznab/release"
+)
+
+// Quality describes the quality of the video of a release, such as
+// WEBDL-1080p or Bluray-2160p Remux. Qualities are totally ordered by
+// resolution, then source, then modifier.
+type Quality struct {
+	// where the video was captured from
+	Source release.Source
+	// vertical resolution of the video
+	Resolution release.Resolution
+	// how the video was processed, such as being remuxed
+	Modifier QualityModifier
+}
+
+// QualityModifier describes how the video of a release was processed
+type QualityModifier int
+
+// modifiers of a Quality, in order
+const (
+	// the video was encoded as usual for its source
+	ModifierNone QualityModifier = iota
+	// the video was remuxed from a disc without being re-encoded
+	ModifierRemux
+)
+
+// qualitySourceNames are the names of each source within the name of a Quality
+var qualitySourceNames = map[release.Source]string{
+	release.SourceCAM:      "CAM",
+	release.SourceTelesync: "TS",
+	release.SourceTelecine: "TC",
+	release.SourceScreener: "SCR",
+	release.SourceSDTV:     "SDTV",
+	release.SourceDVD:      "DVD",
+	release.SourceHDTV:     "HDTV",
+	release.SourceWEBRip:   "WEBRip",
+	release.SourceWEBDL:    "WEBDL",
+	release.SourceBluRay:   "Bluray",
+}
+
+// String returns the name of the quality, such as WEBDL-1080p or
+// Bluray-2160p Remux; parts of it that are unknown are omitted
+func (q Quality) String() string {
+	var parts []string
+	if name, ok := qualitySourceNames[q.Source]; ok {
+		parts = append(parts, name)
+	}
+	if q.Resolution != release.ResolutionUnknown {
+		parts = append(parts, q.Resolution.String())
+	}
+	if len(parts) == 0 {
+		return "Unknown"
+	}
+	name := strings.Join(parts, "-")
+	if q.Modifier == ModifierRemux {
+		name += " Remux"
+	}
+	return name
+}
+
+// ParseQuality parses the name of a quality, as returned by Quality.String
+func ParseQuality(name string) (Quality, error) {
+	var q Quality
+	remaining := strings.TrimSpace(name)
+	if fields := strings.Fields(remaining); len(fields) == 2 && strings.EqualFold(fields[1], "Remux") {
+		q.Modifier = ModifierRemux
+		remaining = fields[0]
+	}
+	if strings.EqualFold(remaining, "Unknown") {
+		return q, nil
+	}
+
+	for _, part := range strings.Split(remaining, "-") {
+		lower := strings.ToLower(part)
+		if lines, err := strconv.Atoi(strings.TrimSuffix(lower, "p")); err == nil && strings.HasSuffix(lower, "p") {
+			q.Resolution = release.Resolution(lines)
+			continue
+		}
+		found := false
+		for source, sourceName := range qualitySourceNames {
+			if strings.EqualFold(part, sourceName) {
+				q.Source, found = source, true
+			}
+		}
+		if !found {
+			return q, errors.Errorf("invalid quality %s", name)
+		}
+	}
+	return q, nil
+}
+
+// Compare returns -1 if the quality is lower than the other, 1 if it is
+// higher, and 0 if they are the same
+func (q Quality) Compare(other Quality) int {
+	switch {
+	case q.Resolution != other.Resolution:
+		return compareInts(int(q.Resolution), int(other.Resolution))
+	case q.Source != other.Source:
+		return compareInts(int(q.Source), int(other.Source))
+	default:
+		return compareInts(int(q.Modifier), int(other.Modifier))
+	}
+}
+
+// compareInts returns -1 if a is less than b, 1 if it is greater, and 0 if
+// they are equal
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// QualityOf returns the quality of an entry, as parsed from its release name.
+// Where the name does not include the source or resolution, they are inferred
+// from the entry's categories if possible, such as 2160p for Movies/UHD.
+func QualityOf(entry Entry) Quality {
+	q := Quality{Source: entry.Release.Source, Resolution: entry.Release.Resolution}
+	if entry.Release.Remux {
+		q.Modifier = ModifierRemux
+	}
+
+	for _, category := range entry.Meta.Categorisation.Categories {
+		if q.Resolution == release.ResolutionUnknown {
+			switch category {
+			case CategoryMoviesUHD, CategoryTVUHD:
+				q.Resolution = release.Resolution2160p
+			case CategoryMoviesHD, CategoryTVHD:
+				// HD categories include 720p, so nothing higher may be assumed
+				q.Resolution = release.Resolution720p
+			case CategoryMoviesSD, CategoryTVSD:
+				q.Resolution = release.Resolution480p
+			}
+		}
+		if q.Source == release.SourceUnknown && category == CategoryMoviesBluRay {
+			q.Source = release.SourceBluRay
+		}
+	}
+	return q
+}
+
+// revision returns the revision of a release; PROPER and REPACK releases,
+// and later versions, supersede others of the same quality
+func revision(r Release) int {
+	revision := 1
+	if r.Version > revision {
+		revision = r.Version
+	}
+	if r.Proper || r.Repack {
+		revision++
+	}
+	return revision
+}
+
+// QualityProfile describes which qualities are wanted, and the quality at
+// which to stop upgrading
+type QualityProfile struct {
+	// qualities that are wanted; every quality is if empty
+	Allowed []Quality
+	// once an entry of this quality or higher is had, only revisions of the
+	// same quality are upgrades; there is no cutoff if it is the zero Quality
+	Cutoff Quality
+}
+
+// Allows returns whether the profile wants a quality
+func (p QualityProfile) Allows(q Quality) bool {
+	if len(p.Allowed) == 0 {
+		return true
+	}
+	for _, allowed := range p.Allowed {
+		if allowed == q {
+			return true
+		}
+	}
+	return false
+}
+
+// CutoffMet returns whether a quality meets the profile's cutoff
+func (p QualityProfile) CutoffMet(q Quality) bool {
+	return p.Cutoff != (Quality{}) && q.Compare(p.Cutoff) >= 0
+}
+
+// IsUpgrade returns whether the candidate quality is an upgrade over the
+// current quality; that is, whether it is allowed, higher, and the current
+// quality does not meet the cutoff
+func (p QualityProfile) IsUpgrade(current, candidate Quality) bool {
+	return p.Allows(candidate) && !p.CutoffMet(current) && candidate.Compare(current) > 0
+}
+
+// IsEntryUpgrade returns whether the candidate entry is an upgrade over the
+// current entry. Besides being of an upgraded quality, an allowed entry of the
+// same quality is an upgrade if it is a later revision, such as a PROPER or
+// REPACK, even once the cutoff is met.
+func (p QualityProfile) IsEntryUpgrade(current, candidate Entry) bool {
+	currentQuality, candidateQuality := QualityOf(current), QualityOf(candidate)
+	if currentQuality == candidateQuality {
+		return p.Allows(candidateQuality) && revision(candidate.Release) > revision(current.Release)
+	}
+	return p.IsUpgrade(currentQuality, candidateQuality)
+}
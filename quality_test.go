@@ -0,0 +1,81 @@
+package newznab
+
+import (
+	"testing"
+
+	"github.com/smquartz/newznab/release"
+)
+
+func TestQualityOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []Category
+		expected   string
+	}{
+		{"White.Collar.S03E05.720p.HDTV.X264-DIMENSION", []Category{CategoryTVHD}, "HDTV-720p"},
+		{"Dune.2021.2160p.UHD.BluRay.REMUX.HDR10.HEVC.TrueHD.7.1-GRP", []Category{CategoryMoviesUHD}, "Bluray-2160p Remux"},
+		{"Some.Movie.2010.WEB-DL.x264-GRP", []Category{CategoryMoviesUHD}, "WEBDL-2160p"},
+		{"Some.Movie.2010.x264-GRP", []Category{CategoryMovies, CategoryMoviesBluRay}, "Bluray"},
+		{"Some.Show.S01E01.x264-GRP", []Category{CategoryTVHD}, "720p"},
+		{"Some.Show.S01E01-GRP", nil, "Unknown"},
+	}
+	for _, test := range tests {
+		var entry Entry
+		entry.Release = release.Parse(test.name)
+		entry.Meta.Categorisation.Categories = test.categories
+		q := QualityOf(entry)
+		if q.String() != test.expected {
+			t.Errorf("Wrong quality for %s: %s", test.name, q)
+		}
+		if parsed, err := ParseQuality(q.String()); err != nil || parsed != q {
+			t.Errorf("Wrong parsed quality for %s: %s, %v", q, parsed, err)
+		}
+	}
+
+	if _, err := ParseQuality("VHS-240p"); err == nil {
+		t.Error("No error for invalid quality")
+	}
+}
+
+func TestQualityProfile(t *testing.T) {
+	quality := func(name string) Quality {
+		q, err := ParseQuality(name)
+		if err != nil {
+			t.Fatalf("Error parsing quality %s: %v", name, err)
+		}
+		return q
+	}
+	ordered := []string{"SDTV-480p", "DVD-480p", "HDTV-720p", "WEBDL-720p", "HDTV-1080p", "WEBRip-1080p", "WEBDL-1080p", "Bluray-1080p", "Bluray-1080p Remux", "Bluray-2160p"}
+	for i := 1; i < len(ordered); i++ {
+		if quality(ordered[i-1]).Compare(quality(ordered[i])) != -1 || quality(ordered[i]).Compare(quality(ordered[i-1])) != 1 {
+			t.Errorf("Wrong order of %s and %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	profile := QualityProfile{
+		Allowed: []Quality{quality("HDTV-720p"), quality("WEBDL-720p"), quality("HDTV-1080p"), quality("WEBDL-1080p")},
+		Cutoff:  quality("HDTV-1080p"),
+	}
+	if !profile.IsUpgrade(quality("HDTV-720p"), quality("WEBDL-1080p")) {
+		t.Error("Higher allowed quality below cutoff is not an upgrade")
+	}
+	if profile.IsUpgrade(quality("HDTV-720p"), quality("Bluray-1080p")) {
+		t.Error("Disallowed quality is an upgrade")
+	}
+	if profile.IsUpgrade(quality("HDTV-1080p"), quality("WEBDL-1080p")) {
+		t.Error("Quality meeting cutoff was upgraded")
+	}
+
+	entry := func(name string) Entry {
+		return Entry{Release: release.Parse(name)}
+	}
+	if !profile.IsEntryUpgrade(entry("Show.S01E01.1080p.HDTV.x264-GRP"), entry("Show.S01E01.PROPER.1080p.HDTV.x264-OTHER")) {
+		t.Error("PROPER of the same quality is not an upgrade")
+	}
+	if profile.IsEntryUpgrade(entry("Show.S01E01.PROPER.1080p.HDTV.x264-GRP"), entry("Show.S01E01.1080p.HDTV.x264-OTHER")) {
+		t.Error("Non-PROPER of the same quality is an upgrade")
+	}
+	if !profile.IsEntryUpgrade(entry("Show.S01E01.720p.HDTV.x264-GRP"), entry("Show.S01E01.720p.WEB-DL.x264-GRP")) {
+		t.Error("Higher quality entry is not an upgrade")
+	}
+}
@@ -0,0 +1,278 @@
+package newznab
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/smquartz/errors"
+)
+
+// SearchQuery describes the parameters of a generic search (t=search)
+type SearchQuery struct {
+	// free text query
+	Query string
+	// categories to restrict the search to
+	Categories []Category
+	// maximum number of results to return
+	Limit int
+	// number of results to skip
+	Offset int
+}
+
+// values validates a SearchQuery, and returns the corresponding API parameters
+func (q SearchQuery) values() (url.Values, error) {
+	v := url.Values{}
+	setString(v, "q", q.Query)
+	setCategories(v, q.Categories)
+	if err := setPaging(v, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// TVQuery describes the parameters of a TV search (t=tvsearch)
+type TVQuery struct {
+	// free text query
+	Query string
+	// season number, or year for daily shows; an S prefix is permitted
+	Season string
+	// episode number, or month and day (MM/DD) for daily shows; an E prefix is
+	// permitted. Requires Season to be set.
+	Episode string
+	// ID of the series in TVRage
+	TVRageID int64
+	// ID of the series in TheTVDB
+	TVDBID int64
+	// ID of the series in TVmaze
+	TVMazeID int64
+	// ID of the series in IMDB, with or without the tt prefix
+	IMDBID string
+	// categories to restrict the search to
+	Categories []Category
+	// maximum number of results to return
+	Limit int
+	// number of results to skip
+	Offset int
+}
+
+var (
+	// matches a valid season parameter
+	seasonPattern = regexp.MustCompile(`(?i)^s?(\d+)$`)
+	// matches a valid episode parameter
+	episodePattern = regexp.MustCompile(`(?i)^(?:e?(\d+)|(\d{1,2}/\d{1,2}))$`)
+)
+
+// values validates a TVQuery, and returns the corresponding API parameters
+func (q TVQuery) values() (url.Values, error) {
+	v := url.Values{}
+	setString(v, "q", q.Query)
+
+	if q.Season != "" {
+		match := seasonPattern.FindStringSubmatch(q.Season)
+		if match == nil {
+			return nil, errors.Errorf("invalid season %s", q.Season)
+		}
+		v.Set("season", match[1])
+	}
+	if q.Episode != "" {
+		if q.Season == "" {
+			return nil, errors.Errorf("episode %s provided without a season", q.Episode)
+		}
+		match := episodePattern.FindStringSubmatch(q.Episode)
+		if match == nil {
+			return nil, errors.Errorf("invalid episode %s", q.Episode)
+		}
+		v.Set("ep", match[1]+match[2])
+	}
+
+	ids := []struct {
+		key   string
+		value int64
+	}{{"rid", q.TVRageID}, {"tvdbid", q.TVDBID}, {"tvmazeid", q.TVMazeID}}
+	for _, id := range ids {
+		if id.value < 0 {
+			return nil, errors.Errorf("invalid %s %d", id.key, id.value)
+		}
+		setInt(v, id.key, id.value)
+	}
+	if q.IMDBID != "" {
+		imdbID, err := normaliseIMDBID(q.IMDBID)
+		if err != nil {
+			return nil, err
+		}
+		v.Set("imdbid", imdbID)
+	}
+
+	setCategories(v, q.Categories)
+	if err := setPaging(v, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MovieQuery describes the parameters of a movie search (t=movie)
+type MovieQuery struct {
+	// free text query
+	Query string
+	// ID of the movie in IMDB, with or without the tt prefix
+	IMDBID string
+	// genre of the movie
+	Genre string
+	// categories to restrict the search to
+	Categories []Category
+	// maximum number of results to return
+	Limit int
+	// number of results to skip
+	Offset int
+}
+
+// values validates a MovieQuery, and returns the corresponding API parameters
+func (q MovieQuery) values() (url.Values, error) {
+	v := url.Values{}
+	setString(v, "q", q.Query)
+	if q.IMDBID != "" {
+		imdbID, err := normaliseIMDBID(q.IMDBID)
+		if err != nil {
+			return nil, err
+		}
+		v.Set("imdbid", imdbID)
+	}
+	setString(v, "genre", q.Genre)
+	setCategories(v, q.Categories)
+	if err := setPaging(v, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// MusicQuery describes the parameters of a music search (t=music)
+type MusicQuery struct {
+	// free text query
+	Query string
+	// name of the artist
+	Artist string
+	// name of the album
+	Album string
+	// name of the publishing label
+	Label string
+	// name of a track on the album
+	Track string
+	// year the album was released
+	Year int
+	// genre of the music
+	Genre string
+	// categories to restrict the search to
+	Categories []Category
+	// maximum number of results to return
+	Limit int
+	// number of results to skip
+	Offset int
+}
+
+// values validates a MusicQuery, and returns the corresponding API parameters
+func (q MusicQuery) values() (url.Values, error) {
+	v := url.Values{}
+	setString(v, "q", q.Query)
+	setString(v, "artist", q.Artist)
+	setString(v, "album", q.Album)
+	setString(v, "label", q.Label)
+	setString(v, "track", q.Track)
+	if q.Year < 0 || q.Year > 9999 {
+		return nil, errors.Errorf("invalid year %d", q.Year)
+	}
+	setInt(v, "year", int64(q.Year))
+	setString(v, "genre", q.Genre)
+	setCategories(v, q.Categories)
+	if err := setPaging(v, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// BookQuery describes the parameters of a book search (t=book)
+type BookQuery struct {
+	// free text query
+	Query string
+	// title of the book
+	Title string
+	// author of the book
+	Author string
+	// categories to restrict the search to
+	Categories []Category
+	// maximum number of results to return
+	Limit int
+	// number of results to skip
+	Offset int
+}
+
+// values validates a BookQuery, and returns the corresponding API parameters
+func (q BookQuery) values() (url.Values, error) {
+	v := url.Values{}
+	setString(v, "q", q.Query)
+	setString(v, "title", q.Title)
+	setString(v, "author", q.Author)
+	setCategories(v, q.Categories)
+	if err := setPaging(v, q.Limit, q.Offset); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// normaliseIMDBID takes an IMDB ID with or without the tt prefix, and returns
+// the numeric form used by the newznab API
+func normaliseIMDBID(id string) (string, error) {
+	numeric := strings.TrimPrefix(strings.ToLower(id), "tt")
+	if _, err := strconv.ParseUint(numeric, 10, 64); err != nil {
+		return "", errors.Errorf("invalid IMDB ID %s", id)
+	}
+	return numeric, nil
+}
+
+// imdbTitleID takes a numeric IMDB ID, and returns it in the tt prefixed form
+// used by IMDB and OMDB
+func imdbTitleID(numeric string) string {
+	return fmt.Sprintf("tt%07s", numeric)
+}
+
+// setPaging validates and sets the limit and offset API parameters
+func setPaging(v url.Values, limit, offset int) error {
+	if limit < 0 {
+		return errors.Errorf("invalid limit %d", limit)
+	}
+	if offset < 0 {
+		return errors.Errorf("invalid offset %d", offset)
+	}
+	setInt(v, "limit", int64(limit))
+	setInt(v, "offset", int64(offset))
+	return nil
+}
+
+// setString sets an API parameter if the value is not empty
+func setString(v url.Values, key, value string) {
+	if value != "" {
+		v.Set(key, value)
+	}
+}
+
+// setInt sets an API parameter if the value is not zero
+func setInt(v url.Values, key string, value int64) {
+	if value != 0 {
+		v.Set(key, strconv.FormatInt(value, 10))
+	}
+}
+
+// setCategories sets the cat API parameter to a comma separated list of
+// category codes, if any categories are provided
+func setCategories(v url.Values, categories []Category) {
+	if len(categories) == 0 {
+		return
+	}
+	codes := make([]string, len(categories))
+	for i, cat := range categories {
+		codes[i] = strconv.Itoa(cat.Code)
+	}
+	v.Set("cat", strings.Join(codes, ","))
+}
@@ -0,0 +1,27 @@
+package newznab
+
+import "testing"
+
+func TestTVQueryValidation(t *testing.T) {
+	invalid := []TVQuery{
+		{Episode: "5"},
+		{Season: "three"},
+		{Season: "3", Episode: "five"},
+		{TVDBID: -1},
+		{IMDBID: "nm0000001"},
+		{Limit: -1},
+	}
+	for _, q := range invalid {
+		if _, err := q.values(); err == nil {
+			t.Errorf("Expected error for query %+v", q)
+		}
+	}
+
+	v, err := TVQuery{Season: "2012", Episode: "02/27", IMDBID: "tt1358522"}.values()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if v.Get("season") != "2012" || v.Get("ep") != "02/27" || v.Get("imdbid") != "1358522" {
+		t.Errorf("Wrong parameters: %v", v)
+	}
+}
@@ -1,10 +1,7 @@
 package newznab
 
-// Release describes a "release", with specific meaning
-// this will be moved to its own package at some point for release name parsing
-type Release struct {
-	// the release group
-	Group string
-	// the release name
-	Name string
-}
+import "github.com/smquartz/newznab/release"
+
+// Release describes a scene release, as parsed from its name by the release
+// package
+type Release = release.Release
@@ -0,0 +1,147 @@
+package release
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// matches the bracketed group at the start of an anime release name, such
+	// as [HorribleSubs] or (Sniper2000)
+	leadingGroupPattern = regexp.MustCompile(`^\s*(?:\[([^\]]+)\]|\(([^)]+)\))[\s_.\-]*`)
+	// matches the bracketed tags at the end of an anime release name, such as
+	// [720p] or (1280x720 x264 AAC)
+	trailingTagsPattern = regexp.MustCompile(`(?:[\s_.\-]*(?:\[[^\]]*\]|\([^)]*\)))+[\s_.\-]*$`)
+	// matches a bracketed tag
+	tagPattern = regexp.MustCompile(`\[([^\]]*)\]|\(([^)]*)\)`)
+	// matches a CRC32 tag
+	crcPattern = regexp.MustCompile(`^[0-9A-Fa-f]{8}$`)
+	// matches a resolution given as width and height, such as 1280x720
+	dimensionsPattern = regexp.MustCompile(`(?i)\b\d{3,4}x(\d{3,4})\b`)
+	// matches an episode following a dash, or a batch of them, such as
+	// - 07, - 07v2 and - 01-12
+	dashEpisodePattern = regexp.MustCompile(`(?i)\s-\s(\d{1,4})(?:v(\d))?(?:\s?[-~]\s?(\d{1,4})(?:v(\d))?)?(?:\s|$)`)
+	// matches an explicitly labelled episode, such as EP07 or #07
+	labelledEpisodePattern = regexp.MustCompile(`(?i)(?:\b(?:EP?|Episode)\s?|#)(\d{1,4})(?:v(\d))?(?:\s|$)`)
+	// matches an episode at the end of the name, such as the 07 of
+	// Frame Arms Girl 07
+	trailingEpisodePattern = regexp.MustCompile(`(?i)\s(\d{1,4})(?:v(\d))?(?:\s?[-~]\s?(\d{1,4})(?:v(\d))?)?$`)
+	// matches a volume, such as Vol.01
+	volumePattern = regexp.MustCompile(`(?i)(?:\s-)?\s(?:Vol(?:ume)?\.?\s?\d+)$`)
+	// matches the terms of a tag that denote a batch
+	batchPattern = term(`Batch|Complete`)
+	// matches the terms of a tag that denote a raw release
+	rawPattern = term(`RAW`)
+	// matches the terms of a tag that denote a subtitled release
+	subPattern = term(`Sub|Subs|Subbed|Subtitled|Softsub|Hardsub`)
+	// matches the terms of a tag that denote a Blu-ray source
+	bdPattern = term(`BD|BDRip`)
+)
+
+// ParseAnime parses an anime release name, such as
+// [HorribleSubs] Kindaichi Case Files R - 23 [480p].mkv, in which the group
+// is bracketed at the start, episodes are numbered from the start of the show,
+// and details of the release are bracketed at the end. Technical details are
+// parsed as they would be for a scene release.
+func ParseAnime(name string) Release {
+	r := parseScene(name)
+	r.Anime = true
+	r.Group = ""
+
+	main := strings.TrimSpace(extensionPattern.ReplaceAllString(strings.TrimSpace(name), ""))
+	if !strings.Contains(main, " ") {
+		main = strings.Replace(main, "_", " ", -1)
+	}
+	if m := leadingGroupPattern.FindStringSubmatch(main); m != nil {
+		r.Group = m[1] + m[2]
+		main = main[len(m[0]):]
+	}
+
+	for _, m := range tagPattern.FindAllStringSubmatch(main, -1) {
+		r.parseTag(m[1] + m[2])
+	}
+	if strings.HasSuffix(strings.ToLower(r.Group), "raws") {
+		r.Raw = true
+	}
+	if strings.HasSuffix(strings.ToLower(r.Group), "subs") {
+		r.Subbed = true
+	}
+
+	main = strings.TrimSpace(trailingTagsPattern.ReplaceAllString(main, ""))
+	if len(r.Episodes) > 0 {
+		// a scene season and episode takes precedence over absolute numbering
+		r.Title = cleanTitle(main)
+		if scene := parseScene(main); scene.Title != "" {
+			r.Title = scene.Title
+		}
+		return r
+	}
+
+	r.AbsoluteEpisodes = nil
+	if loc := dashEpisodePattern.FindStringSubmatchIndex(main); loc != nil {
+		r.setEpisodes(main, loc)
+		main = main[:loc[0]]
+	} else if loc := labelledEpisodePattern.FindStringSubmatchIndex(main); loc != nil {
+		r.setEpisodes(main, loc)
+		main = main[:loc[0]]
+	} else if loc := trailingEpisodePattern.FindStringSubmatchIndex(main); loc != nil && loc[0] > 0 {
+		r.setEpisodes(main, loc)
+		main = main[:loc[0]]
+	} else if loc := volumePattern.FindStringIndex(main); loc != nil {
+		r.Batch = true
+		main = main[:loc[0]]
+	}
+	r.Title = strings.Trim(strings.Join(strings.Fields(main), " "), " -_")
+	return r
+}
+
+// setEpisodes sets the absolute episodes and version of a release from the
+// groups of an episode pattern; the first and second are the first episode
+// and its version, and the optional third and fourth the last episode of a
+// batch and its version
+func (r *Release) setEpisodes(name string, loc []int) {
+	group := func(i int) string {
+		if 2*i+1 >= len(loc) || loc[2*i] < 0 {
+			return ""
+		}
+		return name[loc[2*i]:loc[2*i+1]]
+	}
+
+	first, _ := strconv.Atoi(group(1))
+	r.AbsoluteEpisodes = []int{first}
+	if last, err := strconv.Atoi(group(3)); err == nil && last > first {
+		r.AbsoluteEpisodes = episodeRange(first, last)
+		r.Batch = true
+	}
+	for _, version := range []string{group(2), group(4)} {
+		if v, err := strconv.Atoi(version); err == nil {
+			r.Version = v
+		}
+	}
+}
+
+// parseTag updates a release from the contents of a bracketed tag
+func (r *Release) parseTag(tag string) {
+	tag = strings.TrimSpace(tag)
+	if crcPattern.MatchString(tag) && r.CRC32 == "" {
+		r.CRC32 = strings.ToUpper(tag)
+		return
+	}
+	if m := dimensionsPattern.FindStringSubmatch(tag); m != nil && r.Resolution == ResolutionUnknown {
+		lines, _ := strconv.Atoi(m[1])
+		r.Resolution = Resolution(lines)
+	}
+	if _, ok := find(batchPattern, tag); ok {
+		r.Batch = true
+	}
+	if _, ok := find(rawPattern, tag); ok {
+		r.Raw = true
+	}
+	if _, ok := find(subPattern, tag); ok {
+		r.Subbed = true
+	}
+	if _, ok := find(bdPattern, tag); ok && r.Source == SourceUnknown {
+		r.Source = SourceBluRay
+	}
+}
@@ -0,0 +1,70 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAnime(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Release
+	}{
+		{"[HorribleSubs] Kindaichi Case Files R - 23 [480p].mkv", Release{
+			Title: "Kindaichi Case Files R", AbsoluteEpisodes: []int{23}, Resolution: Resolution480p,
+			Group: "HorribleSubs", Subbed: true,
+		}},
+		{"(Sniper2000) - Pokemon HD - XY 37", Release{
+			Title: "Pokemon HD - XY", AbsoluteEpisodes: []int{37}, Group: "Sniper2000",
+		}},
+		{"[Vivid] Hanayamata - 10v2 [A33D6606]", Release{
+			Title: "Hanayamata", AbsoluteEpisodes: []int{10}, Version: 2, CRC32: "A33D6606", Group: "Vivid",
+		}},
+		{"[finFAGs]_Frame_Arms_Girl_07_(1280x720_TV_AAC)_[1262B6F7].mkv", Release{
+			Title: "Frame Arms Girl", AbsoluteEpisodes: []int{7}, Resolution: Resolution720p, Audio: AudioAAC,
+			CRC32: "1262B6F7", Group: "finFAGs",
+		}},
+		{"[Ohys-Raws] RAIL WARS! - 07 (TBS 1280x720 x264 AAC).mp4", Release{
+			Title: "RAIL WARS!", AbsoluteEpisodes: []int{7}, Resolution: Resolution720p, Codec: CodecH264,
+			Audio: AudioAAC, Group: "Ohys-Raws", Raw: true,
+		}},
+		{"[TSRaws] Futsuu no Joshikousei ga [Locodol] Yattemita. #07 (TBS).ts", Release{
+			Title: "Futsuu no Joshikousei ga [Locodol] Yattemita.", AbsoluteEpisodes: []int{7},
+			Group: "TSRaws", Raw: true,
+		}},
+		{"[JIGGYSUB] KOI KOI 7 EP07 [R2DVD 420P H264 AC3]", Release{
+			Title: "KOI KOI 7", AbsoluteEpisodes: []int{7}, Codec: CodecH264, Audio: AudioDD, Group: "JIGGYSUB",
+		}},
+		{"[Arabasma.com] Naruto Shippuuden - 372 [Arabic Sub] [MQ].mp4", Release{
+			Title: "Naruto Shippuuden", AbsoluteEpisodes: []int{372}, Group: "Arabasma.com", Subbed: true,
+		}},
+		{"[FFF] Ore Monogatari!! - Vol.01 [BD][720p-AAC]", Release{
+			Title: "Ore Monogatari!!", Resolution: Resolution720p, Source: SourceBluRay, Audio: AudioAAC,
+			Group: "FFF", Batch: true,
+		}},
+		{"[Judas] Show - S01E03 [1080p][HEVC x265 10bit].mkv", Release{
+			Title: "Show", Season: 1, Episodes: []int{3}, Resolution: Resolution1080p, Codec: CodecH265,
+			Group: "Judas",
+		}},
+		{"[Coalgirls] Clannad - 01-23 [1080p][Batch]", Release{
+			Title: "Clannad", AbsoluteEpisodes: episodeRange(1, 23), Resolution: Resolution1080p,
+			Group: "Coalgirls", Batch: true,
+		}},
+	}
+
+	for _, test := range tests {
+		test.expected.Name = test.name
+		test.expected.Anime = true
+		if actual := Parse(test.name); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Wrong release for %s:\n%+v\nexpected\n%+v", test.name, actual, test.expected)
+		}
+	}
+
+	// names without a bracketed group are only parsed as anime on request
+	if r := ParseAnime("DAYS - 05 (1280x720 HEVC2 AAC).mkv"); r.Title != "DAYS" || !reflect.DeepEqual(r.AbsoluteEpisodes, []int{5}) || r.Resolution != Resolution720p {
+		t.Errorf("Wrong release: %+v", r)
+	}
+	if r := Parse("[ www.Torrenting.com ] - White.Collar.S03E05.720p.HDTV.X264-DIMENSION"); r.Anime || r.Season != 3 {
+		t.Errorf("Scene release parsed as anime: %+v", r)
+	}
+}
@@ -0,0 +1,393 @@
+package release
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// separators are the characters that separate the terms of a release name
+const separators = `\s._\-\[\]()`
+
+// term returns a case insensitive pattern matching a whole term of a release
+// name; the first group of the pattern is the term itself
+func term(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)(?:^|[` + separators + `])(` + pattern + `)(?:$|[` + separators + `])`)
+}
+
+// caseSensitiveTerm is like term, but its pattern is case sensitive
+func caseSensitiveTerm(pattern string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[` + separators + `])(` + pattern + `)(?:$|[` + separators + `])`)
+}
+
+var (
+	// matches a season and one or more episodes, such as S03E05, S01E01E02,
+	// and S01E01-E03
+	seasonEpisodePattern = term(`S(\d{1,3})[ .]?E(\d{1,4})((?:[ .\-]?E\d{1,4}|-\d{1,4})*)`)
+	// matches the episodes following the first in seasonEpisodePattern
+	extraEpisodePattern = regexp.MustCompile(`(?i)(-)?E?(\d+)`)
+	// matches a season and episode in the form 3x05
+	crossPattern = term(`(\d{1,2})x(\d{2,3})(?:-(\d{2,3}))?`)
+	// matches a season on its own, as used by season packs
+	seasonPattern = term(`(?:S|Season[ .]?)(\d{1,3})`)
+	// matches the air date of an episode of a daily show
+	datePattern = term(`((?:19|20)\d{2})[ .\-](\d{2})[ .\-](\d{2})`)
+	// matches an episode number without a season
+	absolutePattern = term(`(?:E|EP|Episode[ .]?)(\d{1,4})`)
+	// matches a year
+	yearPattern = term(`((?:19|20)\d{2})`)
+	// matches a resolution
+	resolutionPattern = term(`(?:(480|576|720|1080|2160)[pi]|(4K|UHD))`)
+	// matches PROPER releases
+	properPattern = term(`PROPER`)
+	// matches REPACK releases
+	repackPattern = term(`REPACK|RERIP`)
+	// matches remuxes
+	remuxPattern = term(`REMUX`)
+	// matches the audio channel layout following an audio codec
+	channelsPattern = regexp.MustCompile(`([1-7])[ .]([01])$`)
+	// matches the extensions release names sometimes carry
+	extensionPattern = regexp.MustCompile(`(?i)\.(?:mkv|mp4|avi|m4v|ts|nzb|torrent)$`)
+	// matches a site tag following the group, such as -GROUP[rarbg]
+	siteTagPattern = regexp.MustCompile(`\[[^\]]*\]$`)
+	// matches the release group at the end of a name
+	groupPattern = regexp.MustCompile(`-([^\s.\-\[\]()]+)$`)
+)
+
+// sourcePatterns match each Source, in order of precedence
+var sourcePatterns = []struct {
+	source  Source
+	pattern *regexp.Regexp
+}{
+	{SourceBluRay, term(`Blu-?Ray|BDRip|BRRip|BD25|BD50|BDMV|UHD[ .\-]?BluRay`)},
+	{SourceWEBRip, term(`WEB-?Rip`)},
+	{SourceWEBDL, term(`WEB-?DL|WEB`)},
+	{SourceHDTV, term(`HDTV`)},
+	{SourceScreener, term(`DVDSCR|SCREENER|SCR`)},
+	{SourceDVD, term(`DVDRip|DVD-?R|DVD5|DVD9|DVD`)},
+	{SourceSDTV, term(`SDTV|PDTV|DSR|TVRip`)},
+	{SourceTelecine, term(`TC|TELECINE`)},
+	{SourceTelesync, term(`TS|HDTS|TELESYNC`)},
+	{SourceCAM, term(`CAM|CAMRip|HDCAM`)},
+}
+
+// codecPatterns match each Codec, in order of precedence
+var codecPatterns = []struct {
+	codec   Codec
+	pattern *regexp.Regexp
+}{
+	{CodecH265, term(`[xh]\.?265|HEVC`)},
+	{CodecH264, term(`[xh]\.?264|AVC`)},
+	{CodecXviD, term(`XviD`)},
+	{CodecDivX, term(`DivX`)},
+	{CodecVC1, term(`VC-?1`)},
+	{CodecAV1, term(`AV1`)},
+	{CodecMPEG2, term(`MPEG-?2`)},
+}
+
+// audioPatterns match each Audio codec, in order of precedence; the channel
+// layout may follow directly
+var audioPatterns = []struct {
+	audio   Audio
+	pattern *regexp.Regexp
+}{
+	{AudioTrueHD, term(`TrueHD(?:[ .]?[1-7][ .][01])?`)},
+	{AudioDTSHDMA, term(`DTS-?HD[ .\-]?MA(?:[ .]?[1-7][ .][01])?`)},
+	{AudioDTSX, term(`DTS[ .\-:]?X`)},
+	{AudioDDPlus, term(`(?:DDP|DD\+|E-?AC-?3)(?:[ .]?[1-7][ .][01])?`)},
+	{AudioDTS, term(`DTS(?:[ .]?[1-7][ .][01])?`)},
+	{AudioDD, term(`(?:DD|AC-?3)(?:[ .]?[1-7][ .][01])?`)},
+	{AudioAAC, term(`AAC(?:[ .]?[1-7][ .][01])?`)},
+	{AudioFLAC, term(`FLAC`)},
+	{AudioOpus, term(`Opus`)},
+	{AudioMP3, term(`MP3`)},
+	{AudioPCM, term(`L?PCM`)},
+	{AudioAtmos, term(`Atmos`)},
+}
+
+// hdrPatterns match each HDR format
+var hdrPatterns = []struct {
+	hdr     HDR
+	pattern *regexp.Regexp
+}{
+	{HDRDolbyVision, term(`DV|DoVi|Dolby[ .]?Vision`)},
+	{HDR10Plus, term(`HDR10\+|HDR10Plus`)},
+	{HDR10, term(`HDR10`)},
+	{HDRHLG, term(`HLG`)},
+	{HDRGeneric, term(`HDR`)},
+}
+
+// editionPatterns match each edition of a movie
+var editionPatterns = []struct {
+	edition string
+	pattern *regexp.Regexp
+}{
+	{"Director's Cut", term(`Director'?s[ .]Cut`)},
+	{"Extended", term(`Extended(?:[ .](?:Cut|Edition))?`)},
+	{"Theatrical", term(`Theatrical(?:[ .](?:Cut|Edition))?`)},
+	{"Unrated", term(`Unrated`)},
+	{"Uncut", term(`Uncut`)},
+	{"Remastered", term(`Remastered`)},
+	{"IMAX", term(`IMAX`)},
+	{"Criterion", term(`Criterion`)},
+	{"Special Edition", term(`Special[ .]Edition`)},
+}
+
+// languagePattern matches the languages a release names; only upper case
+// terms are languages, as the same words are common in titles
+var languagePattern = caseSensitiveTerm(`MULTi|MULTI|ENGLISH|FRENCH|TRUEFRENCH|VOSTFR|GERMAN|SPANISH|ITALIAN|DUTCH|FLEMISH|SWEDISH|DANISH|NORWEGIAN|FINNISH|NORDIC|POLISH|RUSSIAN|PORTUGUESE|JAPANESE|KOREAN|CHINESE|HINDI`)
+
+// span describes the position of a term within a release name
+type span struct {
+	start, end int
+}
+
+// overlaps returns whether two spans overlap
+func (s span) overlaps(other span) bool {
+	return s.start < other.end && other.start < s.end
+}
+
+// match describes a term matched within a release name
+type match struct {
+	span
+	// the groups of the pattern, after the term itself
+	groups []string
+}
+
+// find returns the first term within the name matching the pattern
+func find(pattern *regexp.Regexp, name string) (match, bool) {
+	matches := findAll(pattern, name)
+	if len(matches) == 0 {
+		return match{}, false
+	}
+	return matches[0], true
+}
+
+// findAll returns every term within the name matching the pattern. Unlike
+// regexp's FindAll, adjacent terms sharing a separator are both matched.
+func findAll(pattern *regexp.Regexp, name string) []match {
+	var matches []match
+	for offset := 0; offset < len(name); {
+		loc := pattern.FindStringSubmatchIndex(name[offset:])
+		if loc == nil {
+			break
+		}
+		m := match{span: span{offset + loc[2], offset + loc[3]}}
+		for i := 4; i < len(loc); i += 2 {
+			if loc[i] < 0 {
+				m.groups = append(m.groups, "")
+				continue
+			}
+			m.groups = append(m.groups, name[offset+loc[i]:offset+loc[i+1]])
+		}
+		matches = append(matches, m)
+		offset = m.end
+	}
+	return matches
+}
+
+// Parse parses a release name. Names that begin with a bracketed group are
+// parsed as anime, as by ParseAnime, unless the rest of the name is a scene
+// release with its own group, in which case the bracket is taken to be a site
+// tag; other names are parsed as scene releases. Parts of the name that are
+// not recognised are left at their zero values; the whole name is the Title if
+// nothing is recognised.
+func Parse(name string) Release {
+	if loc := leadingGroupPattern.FindStringIndex(name); loc != nil {
+		scene := parseScene(name[loc[1]:])
+		if scene.Group == "" || len(scene.Episodes) == 0 && scene.AirDate.IsZero() {
+			return ParseAnime(name)
+		}
+		scene.Name = name
+		return scene
+	}
+	return parseScene(name)
+}
+
+// parseScene parses a scene release name
+func parseScene(name string) Release {
+	r := Release{Name: name}
+	trimmed := strings.TrimSpace(extensionPattern.ReplaceAllString(strings.TrimSpace(name), ""))
+
+	// the title ends at the first recognised term
+	titleEnd := len(trimmed)
+	var markers []span
+	mark := func(s span) {
+		markers = append(markers, s)
+		if s.start < titleEnd {
+			titleEnd = s.start
+		}
+	}
+
+	if m, ok := find(seasonEpisodePattern, trimmed); ok {
+		r.Season, _ = strconv.Atoi(m.groups[0])
+		first, _ := strconv.Atoi(m.groups[1])
+		r.Episodes = append([]int{first}, extraEpisodes(first, m.groups[2])...)
+		mark(m.span)
+	} else if m, ok := find(crossPattern, trimmed); ok {
+		r.Season, _ = strconv.Atoi(m.groups[0])
+		first, _ := strconv.Atoi(m.groups[1])
+		r.Episodes = []int{first}
+		if last, err := strconv.Atoi(m.groups[2]); err == nil {
+			r.Episodes = episodeRange(first, last)
+		}
+		mark(m.span)
+	} else if m, ok := find(datePattern, trimmed); ok && validDate(m.groups) {
+		r.AirDate, _ = time.Parse("2006-01-02", strings.Join(m.groups, "-"))
+		r.Year = r.AirDate.Year()
+		mark(m.span)
+	} else if m, ok := find(seasonPattern, trimmed); ok {
+		r.Season, _ = strconv.Atoi(m.groups[0])
+		mark(m.span)
+	} else if m, ok := find(absolutePattern, trimmed); ok && m.start > 0 {
+		episode, _ := strconv.Atoi(m.groups[0])
+		r.AbsoluteEpisodes = []int{episode}
+		mark(m.span)
+	}
+
+	if m, ok := find(resolutionPattern, trimmed); ok {
+		if m.groups[1] != "" {
+			r.Resolution = Resolution2160p
+		} else {
+			lines, _ := strconv.Atoi(m.groups[0])
+			r.Resolution = Resolution(lines)
+		}
+		mark(m.span)
+	}
+	for _, source := range sourcePatterns {
+		if m, ok := find(source.pattern, trimmed); ok && !overlapsAny(m.span, markers) {
+			r.Source = source.source
+			mark(m.span)
+			break
+		}
+	}
+	if m, ok := find(remuxPattern, trimmed); ok {
+		r.Remux = true
+		mark(m.span)
+	}
+	for _, codec := range codecPatterns {
+		if m, ok := find(codec.pattern, trimmed); ok {
+			r.Codec = codec.codec
+			mark(m.span)
+			break
+		}
+	}
+	for _, audio := range audioPatterns {
+		if m, ok := find(audio.pattern, trimmed); ok && !overlapsAny(m.span, markers) {
+			r.Audio = audio.audio
+			if channels := channelsPattern.FindStringSubmatch(trimmed[m.start:m.end]); channels != nil {
+				r.Channels = channels[1] + "." + channels[2]
+			}
+			mark(m.span)
+			break
+		}
+	}
+	for _, hdr := range hdrPatterns {
+		if m, ok := find(hdr.pattern, trimmed); ok && !overlapsAny(m.span, markers) {
+			r.HDR = append(r.HDR, hdr.hdr)
+			mark(m.span)
+		}
+	}
+	for _, edition := range editionPatterns {
+		if m, ok := find(edition.pattern, trimmed); ok && m.start > 0 {
+			r.Edition = edition.edition
+			mark(m.span)
+			break
+		}
+	}
+	if m, ok := find(properPattern, trimmed); ok {
+		r.Proper = true
+		mark(m.span)
+	}
+	if m, ok := find(repackPattern, trimmed); ok {
+		r.Repack = true
+		mark(m.span)
+	}
+	for _, m := range findAll(languagePattern, trimmed) {
+		if m.start > 0 {
+			r.Languages = append(r.Languages, trimmed[m.start:m.end])
+			mark(m.span)
+		}
+	}
+
+	// a year within the title is part of it, such as 2001 in
+	// 2001.A.Space.Odyssey.1968, so the release year is the last before the
+	// first other recognised term
+	if r.AirDate.IsZero() {
+		for _, m := range findAll(yearPattern, trimmed) {
+			if m.start > 0 && m.start <= titleEnd && !overlapsAny(m.span, markers) {
+				r.Year, _ = strconv.Atoi(m.groups[0])
+				titleEnd = m.start
+			}
+		}
+	}
+
+	r.Title = cleanTitle(trimmed[:titleEnd])
+	r.Group = parseGroup(trimmed, markers)
+	return r
+}
+
+// extraEpisodes parses the episodes following the first of a multi-episode
+// release; a dash denotes a range of episodes
+func extraEpisodes(first int, extra string) []int {
+	var episodes []int
+	last := first
+	for _, m := range extraEpisodePattern.FindAllStringSubmatch(extra, -1) {
+		episode, _ := strconv.Atoi(m[2])
+		if m[1] != "" && episode > last {
+			episodes = append(episodes, episodeRange(last+1, episode)...)
+		} else {
+			episodes = append(episodes, episode)
+		}
+		last = episode
+	}
+	return episodes
+}
+
+// episodeRange returns every episode from first to last inclusive
+func episodeRange(first, last int) []int {
+	var episodes []int
+	for episode := first; episode <= last; episode++ {
+		episodes = append(episodes, episode)
+	}
+	return episodes
+}
+
+// validDate returns whether the year, month, and day groups of a date form a
+// valid date
+func validDate(groups []string) bool {
+	_, err := time.Parse("2006-01-02", strings.Join(groups, "-"))
+	return err == nil
+}
+
+// overlapsAny returns whether a span overlaps any of the provided spans
+func overlapsAny(s span, spans []span) bool {
+	for _, other := range spans {
+		if s.overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanTitle converts the title portion of a release name into a readable
+// title, by replacing separators with spaces
+func cleanTitle(title string) string {
+	if !strings.Contains(title, " ") {
+		title = strings.NewReplacer(".", " ", "_", " ").Replace(title)
+	}
+	return strings.Trim(strings.Join(strings.Fields(title), " "), " -([")
+}
+
+// parseGroup returns the release group at the end of a name, such as
+// DIMENSION in White.Collar.S03E05.720p.HDTV.X264-DIMENSION; a term that
+// was recognised as something else, such as the DL of WEB-DL, is not a group
+func parseGroup(name string, markers []span) string {
+	name = strings.TrimSpace(siteTagPattern.ReplaceAllString(name, ""))
+	loc := groupPattern.FindStringSubmatchIndex(name)
+	if loc == nil || overlapsAny(span{loc[2], loc[3]}, markers) {
+		return ""
+	}
+	return name[loc[2]:loc[3]]
+}
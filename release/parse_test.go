@@ -0,0 +1,71 @@
+package release
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected Release
+	}{
+		{"White.Collar.S03E05.720p.HDTV.X264-DIMENSION", Release{
+			Title: "White Collar", Season: 3, Episodes: []int{5}, Resolution: Resolution720p,
+			Source: SourceHDTV, Codec: CodecH264, Group: "DIMENSION",
+		}},
+		{"Archer.2009.S03E07.PROPER.HDTV.XviD-LOL", Release{
+			Title: "Archer", Year: 2009, Season: 3, Episodes: []int{7}, Source: SourceHDTV,
+			Codec: CodecXviD, Proper: true, Group: "LOL",
+		}},
+		{"Doctor.Who.2005.S07E01-E03.1080p.WEB-DL.DD5.1.H.264-GRP", Release{
+			Title: "Doctor Who", Year: 2005, Season: 7, Episodes: []int{1, 2, 3}, Resolution: Resolution1080p,
+			Source: SourceWEBDL, Audio: AudioDD, Channels: "5.1", Codec: CodecH264, Group: "GRP",
+		}},
+		{"Friends.S01E01E02.REPACK.DVDRip.XviD-GRP", Release{
+			Title: "Friends", Season: 1, Episodes: []int{1, 2}, Source: SourceDVD, Codec: CodecXviD,
+			Repack: true, Group: "GRP",
+		}},
+		{"The.Daily.Show.2012.02.27.Rick.Santorum.HDTV.x264-LMAO", Release{
+			Title: "The Daily Show", Year: 2012, AirDate: time.Date(2012, 2, 27, 0, 0, 0, 0, time.UTC),
+			Source: SourceHDTV, Codec: CodecH264, Group: "LMAO",
+		}},
+		{"Breaking.Bad.S05.1080p.BluRay.x264-ROVERS", Release{
+			Title: "Breaking Bad", Season: 5, Resolution: Resolution1080p, Source: SourceBluRay,
+			Codec: CodecH264, Group: "ROVERS",
+		}},
+		{"The Simpsons 3x05 720p HDTV x264-GRP", Release{
+			Title: "The Simpsons", Season: 3, Episodes: []int{5}, Resolution: Resolution720p,
+			Source: SourceHDTV, Codec: CodecH264, Group: "GRP",
+		}},
+		{"One.Piece.E1000.1080p.WEB.H264-GRP", Release{
+			Title: "One Piece", AbsoluteEpisodes: []int{1000}, Resolution: Resolution1080p,
+			Source: SourceWEBDL, Codec: CodecH264, Group: "GRP",
+		}},
+		{"2001.A.Space.Odyssey.1968.Remastered.2160p.UHD.BluRay.REMUX.HDR10.HEVC.TrueHD.7.1.Atmos-GRP", Release{
+			Title: "2001 A Space Odyssey", Year: 1968, Resolution: Resolution2160p, Source: SourceBluRay,
+			Remux: true, HDR: []HDR{HDR10}, Codec: CodecH265, Audio: AudioTrueHD, Channels: "7.1",
+			Edition: "Remastered", Group: "GRP",
+		}},
+		{"Blade.Runner.1982.Directors.Cut.GERMAN.DL.1080p.BluRay.x264-GRP", Release{
+			Title: "Blade Runner", Year: 1982, Edition: "Director's Cut", Languages: []string{"GERMAN"},
+			Resolution: Resolution1080p, Source: SourceBluRay, Codec: CodecH264, Group: "GRP",
+		}},
+		{"Dune.2021.2160p.WEB-DL.DDP5.1.Atmos.DV.HDR.H.265-GRP[rarbg]", Release{
+			Title: "Dune", Year: 2021, Resolution: Resolution2160p, Source: SourceWEBDL, Audio: AudioDDPlus,
+			Channels: "5.1", HDR: []HDR{HDRDolbyVision, HDRGeneric}, Codec: CodecH265, Group: "GRP",
+		}},
+		{"Some.Movie.2010.1080p.WEB-DL", Release{
+			Title: "Some Movie", Year: 2010, Resolution: Resolution1080p, Source: SourceWEBDL,
+		}},
+		{"Unparseable", Release{Title: "Unparseable"}},
+	}
+
+	for _, test := range tests {
+		test.expected.Name = test.name
+		if actual := Parse(test.name); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("Wrong release for %s:\n%+v\nexpected\n%+v", test.name, actual, test.expected)
+		}
+	}
+}
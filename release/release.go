@@ -0,0 +1,179 @@
+// Package release parses scene release names, such as
+// White.Collar.S03E05.720p.HDTV.X264-DIMENSION, into what they describe
+package release
+
+import (
+	"fmt"
+	"time"
+)
+
+// Release describes a scene release, as parsed from its name
+type Release struct {
+	// the release name
+	Name string
+	// the release group
+	Group string
+	// title of the content, such as the name of a show or movie
+	Title string
+	// year the content was released; 0 if the name does not include it
+	Year int
+	// season number; 0 if the name does not include one
+	Season int
+	// episode numbers within the season, in order; more than one for
+	// multi-episode releases, and none for season packs
+	Episodes []int
+	// episode numbers counted from the start of the show, as used by anime
+	AbsoluteEpisodes []int
+	// date the episode aired, for daily shows
+	AirDate time.Time
+	// vertical resolution of the video
+	Resolution Resolution
+	// where the video was captured from
+	Source Source
+	// whether the video is a remux of a disc, rather than a re-encode
+	Remux bool
+	// codec the video is encoded with
+	Codec Codec
+	// codec the primary audio track is encoded with
+	Audio Audio
+	// channel layout of the primary audio track, such as 5.1
+	Channels string
+	// high dynamic range formats of the video
+	HDR []HDR
+	// edition of a movie, such as Extended or Director's Cut
+	Edition string
+	// whether the release is a PROPER, fixing a problem with another group's
+	// release
+	Proper bool
+	// whether the release is a REPACK or RERIP, fixing a problem with the
+	// group's own release
+	Repack bool
+	// languages of the release, as named in it, such as GERMAN or MULTi
+	Languages []string
+
+	// whether the name was parsed as an anime release, such as
+	// [HorribleSubs] Frame Arms Girl - 07 [720p].mkv
+	Anime bool
+	// version of the release, from a suffix such as the v2 of 07v2; 0 if the
+	// name does not include one
+	Version int
+	// whether the release contains several episodes or volumes, rather than a
+	// single episode
+	Batch bool
+	// CRC32 checksum of the file, as tagged in the name, such as A33D6606
+	CRC32 string
+	// whether the release is raw, without subtitles
+	Raw bool
+	// whether the release is subtitled
+	Subbed bool
+}
+
+// Resolution describes the vertical resolution of a video, in lines
+type Resolution int
+
+// common resolutions
+const (
+	ResolutionUnknown Resolution = 0
+	Resolution480p    Resolution = 480
+	Resolution576p    Resolution = 576
+	Resolution720p    Resolution = 720
+	Resolution1080p   Resolution = 1080
+	Resolution2160p   Resolution = 2160
+)
+
+// String returns the conventional name of the resolution, such as 1080p
+func (r Resolution) String() string {
+	if r == ResolutionUnknown {
+		return "unknown"
+	}
+	return fmt.Sprintf("%dp", int(r))
+}
+
+// Source describes where a video was captured from. Sources are ordered from
+// the lowest to the highest quality.
+type Source int
+
+// sources of video
+const (
+	SourceUnknown Source = iota
+	SourceCAM
+	SourceTelesync
+	SourceTelecine
+	SourceScreener
+	SourceSDTV
+	SourceDVD
+	SourceHDTV
+	SourceWEBRip
+	SourceWEBDL
+	SourceBluRay
+)
+
+// sourceNames are the conventional names of each Source
+var sourceNames = map[Source]string{
+	SourceUnknown:  "unknown",
+	SourceCAM:      "CAM",
+	SourceTelesync: "TS",
+	SourceTelecine: "TC",
+	SourceScreener: "SCR",
+	SourceSDTV:     "SDTV",
+	SourceDVD:      "DVD",
+	SourceHDTV:     "HDTV",
+	SourceWEBRip:   "WEBRip",
+	SourceWEBDL:    "WEB-DL",
+	SourceBluRay:   "BluRay",
+}
+
+// String returns the conventional name of the source, such as WEB-DL
+func (s Source) String() string {
+	if name, ok := sourceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Source(%d)", int(s))
+}
+
+// Codec describes the codec a video is encoded with
+type Codec string
+
+// video codecs
+const (
+	CodecUnknown Codec = ""
+	CodecH264    Codec = "H.264"
+	CodecH265    Codec = "H.265"
+	CodecXviD    Codec = "XviD"
+	CodecDivX    Codec = "DivX"
+	CodecVC1     Codec = "VC-1"
+	CodecAV1     Codec = "AV1"
+	CodecMPEG2   Codec = "MPEG-2"
+)
+
+// Audio describes the codec an audio track is encoded with
+type Audio string
+
+// audio codecs
+const (
+	AudioUnknown Audio = ""
+	AudioTrueHD  Audio = "TrueHD"
+	AudioDTSHDMA Audio = "DTS-HD MA"
+	AudioDTSX    Audio = "DTS:X"
+	AudioDTS     Audio = "DTS"
+	AudioDDPlus  Audio = "DD+"
+	AudioDD      Audio = "DD"
+	AudioAAC     Audio = "AAC"
+	AudioFLAC    Audio = "FLAC"
+	AudioOpus    Audio = "Opus"
+	AudioMP3     Audio = "MP3"
+	AudioPCM     Audio = "PCM"
+	AudioAtmos   Audio = "Atmos"
+)
+
+// HDR describes a high dynamic range format
+type HDR string
+
+// high dynamic range formats
+const (
+	HDRGeneric     HDR = "HDR"
+	HDR10          HDR = "HDR10"
+	HDR10Plus      HDR = "HDR10+"
+	HDRDolbyVision HDR = "DV"
+	HDRHLG         HDR = "HLG"
+)
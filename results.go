@@ -0,0 +1,52 @@
+package newznab
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Results describes the outcome of parsing a feed into entries
+type Results struct {
+	// entries parsed from the feed's items
+	Entries []Entry
+	// describes the items that could not be parsed into entries
+	Errors []ParseError
+	// offset of the first item in the feed within every result of the search
+	Offset int
+	// total number of results of the search, across every page; -1 if the
+	// indexer did not report it
+	Total int
+	// API function called to get the results, such as tvsearch
+	Function string
+	// API parameters sent with the function, other than the API key; those of
+	// a search by ID describe what was asked for, not what the entries are
+	Params url.Values
+}
+
+// items returns the number of items in the feed the Results were parsed from
+func (r Results) items() int {
+	return len(r.Entries) + len(r.Errors)
+}
+
+// ParseError describes why an item in a feed could not be parsed into an
+// Entry
+type ParseError struct {
+	// index of the item within the feed
+	Index int
+	// GUID of the item, as provided in the RSS feed
+	GUID string
+	// the element or attribute of the item that could not be parsed
+	Field string
+	// the underlying error
+	Cause error
+}
+
+// Error implements the error interface for the ParseError type
+func (p ParseError) Error() string {
+	return fmt.Sprintf("unable to parse %s of item %d (%s): %v", p.Field, p.Index, p.GUID, p.Cause)
+}
+
+// Unwrap returns the underlying error
+func (p ParseError) Unwrap() error {
+	return p.Cause
+}
@@ -0,0 +1,296 @@
+package newznab
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	uuid "github.com/satori/go.uuid"
+	"github.com/smquartz/errors"
+)
+
+// namespaces used in newznab feeds
+const (
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	newznabNamespace = "http://www.newznab.com/DTD/2010/feeds/attributes/"
+	torznabNamespace = "http://torznab.com/schemas/2015/feed"
+)
+
+// FeedFormat describes the dialect of RSS a feed is encoded in
+type FeedFormat int
+
+// formats of RSS feeds
+const (
+	// items' attributes are newznab:attr elements
+	FeedNewznab FeedFormat = iota
+	// items' attributes are torznab:attr elements
+	FeedTorznab
+)
+
+// Channel describes the channel of an RSS feed
+type Channel struct {
+	// title of the feed
+	Title string
+	// description of the feed
+	Description string
+	// URL of the website the feed belongs to
+	Link string
+	// language the feed is written in, such as en-gb
+	Language string
+}
+
+// ChannelFromFeed returns the Channel describing a gofeed.Feed, such as that
+// entries were obtained from
+func ChannelFromFeed(feed gofeed.Feed) Channel {
+	return Channel{Title: feed.Title, Description: feed.Description, Link: feed.Link, Language: feed.Language}
+}
+
+// EncodeFeed writes an RSS document of the provided format containing the
+// provided results. Items link to the details and files the entries were
+// obtained with, and their attributes describe everything the Entry model
+// carries, such that parsing the document yields equal entries.
+func EncodeFeed(w io.Writer, channel Channel, results Results, format FeedFormat) error {
+	feed := newRSSFeed(channel, results, entryLinks{}, format)
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errors.Wrapf(err, "unable to write feed", 1)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return errors.Wrapf(err, "unable to encode feed", 1)
+	}
+	return nil
+}
+
+// content types of descriptor files
+const (
+	nzbContentType     = "application/x-nzb"
+	torrentContentType = "application/x-bittorrent"
+)
+
+// rssFeed describes a newznab RSS feed, for encoding
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Newznab string     `xml:"xmlns:newznab,attr"`
+	Torznab string     `xml:"xmlns:torznab,attr,omitempty"`
+	Channel rssChannel `xml:"channel"`
+}
+
+// rssChannel describes the channel of a newznab RSS feed
+type rssChannel struct {
+	Title       string       `xml:"title"`
+	Description string       `xml:"description"`
+	Link        string       `xml:"link"`
+	Language    string       `xml:"language,omitempty"`
+	Response    *rssResponse `xml:"newznab:response"`
+	Items       []rssItem    `xml:"item"`
+}
+
+// rssResponse describes the position of a feed's items within every result
+// of a search
+type rssResponse struct {
+	Offset int `xml:"offset,attr"`
+	Total  int `xml:"total,attr"`
+}
+
+// rssItem describes an item of a newznab RSS feed
+type rssItem struct {
+	Title       string        `xml:"title"`
+	GUID        rssGUID       `xml:"guid"`
+	Link        string        `xml:"link,omitempty"`
+	PubDate     string        `xml:"pubDate"`
+	Categories  []string      `xml:"category"`
+	Description string        `xml:"description,omitempty"`
+	Enclosure   *rssEnclosure `xml:"enclosure"`
+	Attrs       []rssAttr     `xml:"newznab:attr"`
+	// attributes of items of torznab feeds
+	TorznabAttrs []rssAttr `xml:"torznab:attr"`
+}
+
+// rssGUID describes the GUID of an item
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// rssEnclosure describes the descriptor file of an item
+type rssEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// rssAttr describes a newznab attribute of an item
+type rssAttr struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// rssAttrs accumulates the attributes of an item
+type rssAttrs []rssAttr
+
+// add adds an attribute, unless its value is empty
+func (a *rssAttrs) add(name, value string) {
+	if value != "" {
+		*a = append(*a, rssAttr{Name: name, Value: value})
+	}
+}
+
+// addInt adds an integer attribute, unless it is zero
+func (a *rssAttrs) addInt(name string, value int64) {
+	if value != 0 {
+		a.add(name, strconv.FormatInt(value, 10))
+	}
+}
+
+// addFloat adds a floating point attribute of the provided bit size, unless
+// it is zero
+func (a *rssAttrs) addFloat(name string, value float64, bitSize int) {
+	if value != 0 {
+		a.add(name, strconv.FormatFloat(value, 'f', -1, bitSize))
+	}
+}
+
+// addTime adds a date attribute, unless it is the zero time
+func (a *rssAttrs) addTime(name string, value time.Time) {
+	if !value.IsZero() {
+		a.add(name, value.Format(time.RFC1123Z))
+	}
+}
+
+// rssLinks builds the links of an item to its details and descriptor file
+type rssLinks interface {
+	// URL of the entry's details
+	details(entry Entry) string
+	// URL of the entry's descriptor file
+	download(entry Entry) string
+}
+
+// entryLinks links items to the details and descriptor files their entries
+// were obtained with
+type entryLinks struct{}
+
+// details implements the rssLinks interface for the entryLinks type
+func (entryLinks) details(entry Entry) string {
+	return entry.Meta.Source.Item.GUID
+}
+
+// download implements the rssLinks interface for the entryLinks type
+func (entryLinks) download(entry Entry) string {
+	if entry.File != nil && entry.File.URL() != nil {
+		return entry.File.URL().String()
+	}
+	return entry.Meta.Source.Item.Link
+}
+
+// newRSSFeed returns a feed of the provided format containing the provided
+// results
+func newRSSFeed(channel Channel, results Results, links rssLinks, format FeedFormat) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		Atom:    atomNamespace,
+		Newznab: newznabNamespace,
+		Channel: rssChannel{Title: channel.Title, Description: channel.Description, Link: channel.Link, Language: channel.Language},
+	}
+	if format == FeedTorznab {
+		feed.Torznab = torznabNamespace
+	}
+	total := results.Total
+	if total < 0 {
+		total = results.Offset + len(results.Entries)
+	}
+	feed.Channel.Response = &rssResponse{Offset: results.Offset, Total: total}
+	for _, entry := range results.Entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItemFromEntry(entry, links, format))
+	}
+	return feed
+}
+
+// hexGUID returns the GUID of an entry in the form used by newznab
+func hexGUID(entry Entry) string {
+	return strings.Replace(entry.Meta.GUID.String(), "-", "", -1)
+}
+
+// rssItemFromEntry returns an item of the provided format describing an entry
+func rssItemFromEntry(entry Entry, links rssLinks, format FeedFormat) rssItem {
+	details := links.details(entry)
+	item := rssItem{
+		Title:       entry.Release.Name,
+		GUID:        rssGUID{IsPermaLink: isAbsoluteURL(details), Value: details},
+		Link:        links.download(entry),
+		PubDate:     entry.Meta.Dates.Published.Format(time.RFC1123Z),
+		Description: entry.Meta.Source.Item.Description,
+		Categories:  entry.Meta.Categorisation.RSSCategories,
+	}
+	if details == "" {
+		item.GUID.Value = hexGUID(entry)
+	}
+
+	var attrs rssAttrs
+	for _, category := range entry.Meta.Categorisation.Categories {
+		attrs.add("category", strconv.Itoa(category.Code))
+	}
+	if entry.File != nil {
+		item.Enclosure = &rssEnclosure{URL: item.Link, Type: nzbContentType}
+		if _, ok := entry.File.(*Torrent); ok {
+			item.Enclosure.Type = torrentContentType
+		}
+		if size := entry.File.Size(); size > 0 {
+			item.Enclosure.Length = size
+			attrs.addInt("size", size)
+		}
+		if numFiles := entry.File.NumFiles(); numFiles > 0 {
+			attrs.addInt("files", int64(numFiles))
+		}
+		if entry.File.Passworded() {
+			attrs.add("password", "1")
+		}
+	}
+	if !uuid.Equal(entry.Meta.GUID, uuid.Nil) {
+		attrs.add("guid", hexGUID(entry))
+	}
+	attrs.add("poster", entry.Meta.Authoring.NNTPPoster)
+	for _, group := range entry.Meta.Authoring.NNTPGroups {
+		attrs.add("group", group)
+	}
+	attrs.add("team", entry.Meta.Authoring.Team)
+	attrs.addInt("grabs", entry.Meta.Grabs)
+	attrs.addInt("comments", entry.Meta.NumComments)
+	attrs.addTime("usenetdate", entry.Meta.Dates.PublishedUsenet)
+	if entry.Meta.NFO != nil {
+		attrs.add("info", entry.Meta.NFO.String())
+	}
+
+	switch content := entry.Content.(type) {
+	case TV:
+		content.attrs(&attrs)
+	case Movie:
+		content.attrs(&attrs)
+	case Music:
+		content.attrs(&attrs)
+	case Book:
+		content.attrs(&attrs)
+	}
+	if torrent, ok := entry.File.(*Torrent); ok {
+		torrent.attrs(&attrs)
+	}
+
+	if format == FeedTorznab {
+		item.TorznabAttrs = attrs
+	} else {
+		item.Attrs = attrs
+	}
+	return item
+}
+
+// isAbsoluteURL returns whether a string is an absolute URL
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
@@ -0,0 +1,91 @@
+package newznab
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestEncodeFeedRoundTrip(t *testing.T) {
+	samples := []struct {
+		path   string
+		kind   contentKind
+		format FeedFormat
+	}{
+		{"samples/newznab/newznab_nzb_su.xml", contentAuto, FeedNewznab},
+		{"samples/newznab/newznab_tv_attrs.xml", contentAuto, FeedNewznab},
+		{"samples/newznab/newznab_movie.xml", contentMovie, FeedNewznab},
+		{"samples/newznab/newznab_music.xml", contentMusic, FeedNewznab},
+		{"samples/newznab/newznab_book.xml", contentBook, FeedNewznab},
+		{"samples/torznab/torznab_hdaccess_net.xml", contentAuto, FeedTorznab},
+		{"samples/torznab/torznab_tpb.xml", contentAuto, FeedTorznab},
+		{"samples/torznab/torznab_animetosho.xml", contentAuto, FeedTorznab},
+	}
+	for _, sample := range samples {
+		entries := entriesFromSample(t, sample.path, sample.kind)
+		channel := Channel{Title: "Test", Description: "Test feed", Link: "https://example.com/"}
+		results := Results{Entries: entries, Total: len(entries)}
+
+		var encoded bytes.Buffer
+		if err := EncodeFeed(&encoded, channel, results, sample.format); err != nil {
+			t.Fatalf("Error encoding %s: %v", sample.path, err)
+		}
+		feed, err := gofeed.NewParser().Parse(bytes.NewReader(encoded.Bytes()))
+		if err != nil {
+			t.Fatalf("Error parsing encoded %s: %v", sample.path, err)
+		}
+		if ChannelFromFeed(*feed) != channel {
+			t.Errorf("Wrong channel for %s: %+v", sample.path, ChannelFromFeed(*feed))
+		}
+		decoded, err := resultsFromFeed(*feed, sample.kind, true)
+		if err != nil {
+			t.Fatalf("Error parsing encoded entries of %s: %v", sample.path, err)
+		}
+		if decoded.Total != len(entries) || len(decoded.Entries) != len(entries) {
+			t.Fatalf("Wrong number of entries for %s: %d of %d", sample.path, len(decoded.Entries), decoded.Total)
+		}
+
+		for i, entry := range entries {
+			got := decoded.Entries[i]
+			if got.Meta.Source.Item.GUID != entry.Meta.Source.Item.GUID {
+				t.Errorf("Wrong GUID for %s entry %d: %s", sample.path, i, got.Meta.Source.Item.GUID)
+			}
+			if got.Meta.Source.Item.Description != entry.Meta.Source.Item.Description {
+				t.Errorf("Wrong description for %s entry %d: %s", sample.path, i, got.Meta.Source.Item.Description)
+			}
+			if !reflect.DeepEqual(got.Meta.Source.Item.Categories, entry.Meta.Source.Item.Categories) {
+				t.Errorf("Wrong categories for %s entry %d: %v", sample.path, i, got.Meta.Source.Item.Categories)
+			}
+			// attributes which are not modelled are dropped, but none may be added
+			sourceAttrs := attrNames(entry.Meta.Source.Item)
+			for name := range attrNames(got.Meta.Source.Item) {
+				if !sourceAttrs[name] {
+					t.Errorf("Attribute %s of %s entry %d is not in the source", name, sample.path, i)
+				}
+			}
+			got.Meta.Source, entry.Meta.Source = Source{}, Source{}
+			if !got.Meta.Dates.Published.Equal(entry.Meta.Dates.Published) {
+				t.Errorf("Wrong published date for %s entry %d: %v", sample.path, i, got.Meta.Dates.Published)
+			}
+			got.Meta.Dates.Published = entry.Meta.Dates.Published
+			if !reflect.DeepEqual(got, entry) {
+				t.Errorf("Wrong entry for %s entry %d:\n%+v\nwanted\n%+v", sample.path, i, got, entry)
+			}
+		}
+	}
+}
+
+// attrNames returns the names of the attributes an item carries, whatever
+// their namespace; an enclosure length counts as its size
+func attrNames(item gofeed.Item) map[string]bool {
+	names := make(map[string]bool)
+	for _, attr := range itemAttrs(item) {
+		names[attr.Attrs["name"]] = true
+	}
+	if len(item.Enclosures) > 0 && item.Enclosures[0].Length != "" && item.Enclosures[0].Length != "0" {
+		names["size"] = true
+	}
+	return names
+}
@@ -0,0 +1,415 @@
+package newznab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smquartz/errors"
+)
+
+// Rule scores a single aspect of an entry, for use by a Scorer
+type Rule interface {
+	// name of the rule, used in the breakdown of scores
+	Name() string
+	// returns the score the rule gives the entry, and the reason for it
+	Score(entry Entry) (score float64, reason string)
+}
+
+// ruleFunc adapts a function to the Rule interface
+type ruleFunc struct {
+	name  string
+	score func(Entry) (float64, string)
+}
+
+// Name implements the Rule interface for the ruleFunc type
+func (r ruleFunc) Name() string {
+	return r.name
+}
+
+// Score implements the Rule interface for the ruleFunc type
+func (r ruleFunc) Score(entry Entry) (float64, string) {
+	return r.score(entry)
+}
+
+// NewRule returns a Rule with the provided name, which scores entries using
+// the provided function
+func NewRule(name string, score func(Entry) (float64, string)) Rule {
+	return ruleFunc{name: name, score: score}
+}
+
+// Scorer scores and ranks entries by the sum of the scores of its rules
+type Scorer struct {
+	// rules the score of an entry is the sum of
+	Rules []Rule
+}
+
+// Score describes the score a Scorer gave an entry
+type Score struct {
+	// the entry scored
+	Entry Entry
+	// the sum of the scores of every rule
+	Total float64
+	// the score each rule gave the entry, in the order of the Scorer's rules
+	Breakdown []RuleScore
+}
+
+// RuleScore describes the score a single rule gave an entry
+type RuleScore struct {
+	// name of the rule
+	Rule string
+	// score the rule gave the entry
+	Score float64
+	// why the rule gave the entry its score
+	Reason string
+}
+
+// Score scores an entry using every rule of the Scorer
+func (s Scorer) Score(entry Entry) Score {
+	score := Score{Entry: entry}
+	for _, rule := range s.Rules {
+		value, reason := rule.Score(entry)
+		score.Total += value
+		score.Breakdown = append(score.Breakdown, RuleScore{Rule: rule.Name(), Score: value, Reason: reason})
+	}
+	return score
+}
+
+// Rank scores every entry, and returns their scores from the highest to the
+// lowest; entries with equal scores remain in the order provided
+func (s Scorer) Rank(entries []Entry) []Score {
+	scores := make([]Score, len(entries))
+	for i, entry := range entries {
+		scores[i] = s.Score(entry)
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Total > scores[j].Total
+	})
+	return scores
+}
+
+// CategoryRule scores entries by their categories. The weight of a category
+// also applies to its subcategories, unless they have their own.
+type CategoryRule map[int]float64
+
+// Name implements the Rule interface for the CategoryRule type
+func (r CategoryRule) Name() string {
+	return "category"
+}
+
+// Score implements the Rule interface for the CategoryRule type; the highest
+// weight of the entry's categories is its score
+func (r CategoryRule) Score(entry Entry) (float64, string) {
+	var best float64
+	var reason string
+	for _, category := range entry.Meta.Categorisation.Categories {
+		weight, ok := r[category.Code]
+		if !ok {
+			weight, ok = r[category.Code-category.Code%1000]
+		}
+		if ok && (reason == "" || weight > best) {
+			best, reason = weight, fmt.Sprintf("category %d", category.Code)
+		}
+	}
+	if reason == "" {
+		return 0, "no preferred category"
+	}
+	return best, reason
+}
+
+// ResolutionRule scores entries by the resolution parsed from their release
+// name, keyed by its conventional name such as 1080p
+type ResolutionRule map[string]float64
+
+// Name implements the Rule interface for the ResolutionRule type
+func (r ResolutionRule) Name() string {
+	return "resolution"
+}
+
+// Score implements the Rule interface for the ResolutionRule type
+func (r ResolutionRule) Score(entry Entry) (float64, string) {
+	resolution := entry.Release.Resolution.String()
+	return r[resolution], "resolution " + resolution
+}
+
+// CodecRule scores entries by the video codec parsed from their release name,
+// keyed by its name such as H.265
+type CodecRule map[string]float64
+
+// Name implements the Rule interface for the CodecRule type
+func (r CodecRule) Name() string {
+	return "codec"
+}
+
+// Score implements the Rule interface for the CodecRule type
+func (r CodecRule) Score(entry Entry) (float64, string) {
+	if entry.Release.Codec == "" {
+		return 0, "unknown codec"
+	}
+	return r[string(entry.Release.Codec)], "codec " + string(entry.Release.Codec)
+}
+
+// GroupRule scores entries by their release group, keyed by its name; names
+// are compared case insensitively
+type GroupRule map[string]float64
+
+// NewGroupRule returns a GroupRule with the provided weights keyed by the
+// lower case name of their group, or an error if a group is given twice
+func NewGroupRule(weights map[string]float64) (GroupRule, error) {
+	r := make(GroupRule, len(weights))
+	for group, weight := range weights {
+		lower := strings.ToLower(group)
+		if _, ok := r[lower]; ok {
+			return nil, errors.Errorf("group %s configured more than once", group)
+		}
+		r[lower] = weight
+	}
+	return r, nil
+}
+
+// Name implements the Rule interface for the GroupRule type
+func (r GroupRule) Name() string {
+	return "group"
+}
+
+// Score implements the Rule interface for the GroupRule type
+func (r GroupRule) Score(entry Entry) (float64, string) {
+	if entry.Release.Group == "" {
+		return 0, "unknown group"
+	}
+	// keys not built by NewGroupRule may be in any case; where several match,
+	// the highest weight is used so the score does not depend on map order
+	var best float64
+	var found bool
+	for group, weight := range r {
+		if strings.EqualFold(group, entry.Release.Group) && (!found || weight > best) {
+			best, found = weight, true
+		}
+	}
+	return best, "group " + entry.Release.Group
+}
+
+// SizeRule scores entries by whether their size is within a band
+type SizeRule struct {
+	// smallest size within the band, in bytes; unbounded if zero
+	Min int64
+	// largest size within the band, in bytes; unbounded if zero
+	Max int64
+	// score of entries within the band; entries outside of it score the
+	// negative of it, and entries of unknown size zero
+	Weight float64
+}
+
+// Name implements the Rule interface for the SizeRule type
+func (r SizeRule) Name() string {
+	return "size"
+}
+
+// Score implements the Rule interface for the SizeRule type
+func (r SizeRule) Score(entry Entry) (float64, string) {
+	if entry.File == nil || entry.File.Size() <= 0 {
+		return 0, "unknown size"
+	}
+	size := entry.File.Size()
+	if (r.Min > 0 && size < r.Min) || (r.Max > 0 && size > r.Max) {
+		return -r.Weight, fmt.Sprintf("size %d outside of band", size)
+	}
+	return r.Weight, fmt.Sprintf("size %d within band", size)
+}
+
+// AgeRule scores entries by how long ago they were posted to usenet, or else
+// published; the score halves every HalfLife
+type AgeRule struct {
+	// score of an entry posted now
+	Weight float64
+	// how long it takes for the score to halve
+	HalfLife time.Duration
+	// the time ages are measured from; the current time if zero
+	Now time.Time
+}
+
+// Name implements the Rule interface for the AgeRule type
+func (r AgeRule) Name() string {
+	return "age"
+}
+
+// Score implements the Rule interface for the AgeRule type
+func (r AgeRule) Score(entry Entry) (float64, string) {
+	posted := entry.Meta.Dates.PublishedUsenet
+	if posted.IsZero() {
+		posted = entry.Meta.Dates.Published
+	}
+	if posted.IsZero() || r.HalfLife <= 0 {
+		return 0, "unknown age"
+	}
+	now := r.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	age := now.Sub(posted)
+	if age < 0 {
+		age = 0
+	}
+	return r.Weight * math.Pow(0.5, float64(age)/float64(r.HalfLife)), "age " + age.Round(time.Hour).String()
+}
+
+// GrabsRule scores entries by the logarithm of how many times they have been
+// downloaded, multiplied by the weight
+type GrabsRule float64
+
+// Name implements the Rule interface for the GrabsRule type
+func (r GrabsRule) Name() string {
+	return "grabs"
+}
+
+// Score implements the Rule interface for the GrabsRule type
+func (r GrabsRule) Score(entry Entry) (float64, string) {
+	grabs := entry.Meta.Grabs
+	if grabs < 0 {
+		grabs = 0
+	}
+	return float64(r) * math.Log10(1+float64(grabs)), fmt.Sprintf("%d grabs", grabs)
+}
+
+// SeedersRule scores torrents by the logarithm of their number of seeders,
+// multiplied by the weight
+type SeedersRule float64
+
+// Name implements the Rule interface for the SeedersRule type
+func (r SeedersRule) Name() string {
+	return "seeders"
+}
+
+// Score implements the Rule interface for the SeedersRule type
+func (r SeedersRule) Score(entry Entry) (float64, string) {
+	torrent, ok := entry.File.(*Torrent)
+	if !ok || torrent == nil {
+		return 0, "unknown seeders"
+	}
+	return float64(r) * math.Log10(1+float64(torrent.Seeders())), fmt.Sprintf("%d seeders", torrent.Seeders())
+}
+
+// PasswordedRule scores passworded entries with the weight, which is usually
+// negative, and others zero
+type PasswordedRule float64
+
+// Name implements the Rule interface for the PasswordedRule type
+func (r PasswordedRule) Name() string {
+	return "passworded"
+}
+
+// Score implements the Rule interface for the PasswordedRule type
+func (r PasswordedRule) Score(entry Entry) (float64, string) {
+	if entry.File != nil && entry.File.Passworded() {
+		return float64(r), "passworded"
+	}
+	return 0, "not passworded"
+}
+
+// ScorerConfig describes the rules of a Scorer, as loaded from a JSON file
+// by LoadScorer. Rules that are not configured are not used.
+type ScorerConfig struct {
+	// weights of categories, keyed by category code
+	Categories map[string]float64 `json:"categories,omitempty"`
+	// weights of resolutions, keyed by name such as 1080p
+	Resolutions map[string]float64 `json:"resolutions,omitempty"`
+	// weights of video codecs, keyed by name such as H.265
+	Codecs map[string]float64 `json:"codecs,omitempty"`
+	// weights of release groups, keyed by name, which is case insensitive
+	Groups map[string]float64 `json:"groups,omitempty"`
+	// size band
+	Size *struct {
+		Min    int64   `json:"min"`
+		Max    int64   `json:"max"`
+		Weight float64 `json:"weight"`
+	} `json:"size,omitempty"`
+	// age weighting; the half life is a duration such as 168h
+	Age *struct {
+		Weight   float64 `json:"weight"`
+		HalfLife string  `json:"halfLife"`
+	} `json:"age,omitempty"`
+	// weight of the logarithm of grabs
+	Grabs *float64 `json:"grabs,omitempty"`
+	// weight of the logarithm of seeders
+	Seeders *float64 `json:"seeders,omitempty"`
+	// score of passworded entries
+	Passworded *float64 `json:"passworded,omitempty"`
+}
+
+// Scorer returns a Scorer with the configured rules
+func (c ScorerConfig) Scorer() (Scorer, error) {
+	var s Scorer
+	if len(c.Categories) > 0 {
+		categories := make(CategoryRule)
+		for code, weight := range c.Categories {
+			intCode, err := strconv.Atoi(code)
+			if err != nil {
+				return s, errors.Errorf("invalid category code %s", code)
+			}
+			categories[intCode] = weight
+		}
+		s.Rules = append(s.Rules, categories)
+	}
+	if len(c.Resolutions) > 0 {
+		s.Rules = append(s.Rules, ResolutionRule(c.Resolutions))
+	}
+	if len(c.Codecs) > 0 {
+		s.Rules = append(s.Rules, CodecRule(c.Codecs))
+	}
+	if len(c.Groups) > 0 {
+		groups, err := NewGroupRule(c.Groups)
+		if err != nil {
+			return s, err
+		}
+		s.Rules = append(s.Rules, groups)
+	}
+	if c.Size != nil {
+		s.Rules = append(s.Rules, SizeRule{Min: c.Size.Min, Max: c.Size.Max, Weight: c.Size.Weight})
+	}
+	if c.Age != nil {
+		halfLife, err := time.ParseDuration(c.Age.HalfLife)
+		if err != nil {
+			return s, errors.Wrapf(err, "invalid age half life %s", 1, c.Age.HalfLife)
+		}
+		s.Rules = append(s.Rules, AgeRule{Weight: c.Age.Weight, HalfLife: halfLife})
+	}
+	if c.Grabs != nil {
+		s.Rules = append(s.Rules, GrabsRule(*c.Grabs))
+	}
+	if c.Seeders != nil {
+		s.Rules = append(s.Rules, SeedersRule(*c.Seeders))
+	}
+	if c.Passworded != nil {
+		s.Rules = append(s.Rules, PasswordedRule(*c.Passworded))
+	}
+	return s, nil
+}
+
+// LoadScorer reads a JSON ScorerConfig, and returns a Scorer with the
+// configured rules
+func LoadScorer(r io.Reader) (Scorer, error) {
+	var config ScorerConfig
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Scorer{}, errors.Wrapf(err, "unable to parse scorer config", 1)
+	}
+	return config.Scorer()
+}
+
+// LoadScorerFile reads a JSON ScorerConfig from the file at the provided path,
+// and returns a Scorer with the configured rules
+func LoadScorerFile(path string) (Scorer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Scorer{}, errors.Wrapf(err, "unable to open scorer config %s", 1, path)
+	}
+	defer f.Close()
+	return LoadScorer(f)
+}
@@ -0,0 +1,108 @@
+package newznab
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/smquartz/newznab/release"
+)
+
+func TestScorerRank(t *testing.T) {
+	config := `{
+		"categories": {"5000": 1, "5040": 2},
+		"resolutions": {"1080p": 10, "720p": 5},
+		"codecs": {"H.265": 3},
+		"groups": {"Dimension": 4},
+		"size": {"min": 500000000, "max": 5000000000, "weight": 2},
+		"age": {"weight": 8, "halfLife": "24h"},
+		"grabs": 1,
+		"passworded": -100
+	}`
+	scorer, err := LoadScorer(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("Error loading scorer: %v", err)
+	}
+	if len(scorer.Rules) != 8 {
+		t.Fatalf("Wrong number of rules: %d", len(scorer.Rules))
+	}
+	now := time.Date(2012, 2, 28, 0, 0, 0, 0, time.UTC)
+	for i, rule := range scorer.Rules {
+		if age, ok := rule.(AgeRule); ok {
+			age.Now = now
+			scorer.Rules[i] = age
+		}
+	}
+
+	entry := func(name string, size int64, category Category, posted time.Time, grabs int64, passworded bool) Entry {
+		var e Entry
+		e.Release = release.Parse(name)
+		e.Meta.Categorisation.Categories = []Category{category}
+		e.Meta.Dates.PublishedUsenet = posted
+		e.Meta.Grabs = grabs
+		e.File = new(NZB)
+		e.File.setSize(size)
+		e.File.setPassworded(passworded)
+		return e
+	}
+	entries := []Entry{
+		entry("White.Collar.S03E05.720p.HDTV.X264-DIMENSION", 1183105773, CategoryTVHD, now.Add(-24*time.Hour), 99, false),
+		entry("White.Collar.S03E05.1080p.WEB-DL.H.265-OTHER", 2000000000, CategoryTVHD, now, 9, true),
+		entry("White.Collar.S03E05.HDTV.XviD-LOL", 367001600, CategoryFromCode(5030), now.Add(-48*time.Hour), 0, false),
+	}
+
+	scores := scorer.Rank(entries)
+	expected := []string{"DIMENSION", "LOL", "OTHER"}
+	for i, group := range expected {
+		if scores[i].Entry.Release.Group != group {
+			t.Errorf("Wrong entry %d: %s with %f", i, scores[i].Entry.Release.Group, scores[i].Total)
+		}
+	}
+
+	// 2 (category) + 5 (720p) + 0 (codec) + 4 (group) + 2 (size) + 4 (age) + 2 (grabs) + 0 (passworded)
+	if scores[0].Total != 19 {
+		t.Errorf("Wrong total: %f", scores[0].Total)
+	}
+	if len(scores[0].Breakdown) != 8 || scores[0].Breakdown[1].Rule != "resolution" || scores[0].Breakdown[1].Score != 5 || scores[0].Breakdown[1].Reason != "resolution 720p" {
+		t.Errorf("Wrong breakdown: %+v", scores[0].Breakdown)
+	}
+}
+
+func TestNewRule(t *testing.T) {
+	scorer := Scorer{Rules: []Rule{NewRule("proper", func(e Entry) (float64, string) {
+		if e.Release.Proper {
+			return 1, "proper"
+		}
+		return 0, "not proper"
+	})}}
+	score := scorer.Score(Entry{Release: release.Parse("Archer.2009.S03E07.PROPER.HDTV.XviD-LOL")})
+	if score.Total != 1 || score.Breakdown[0].Rule != "proper" || score.Breakdown[0].Reason != "proper" {
+		t.Errorf("Wrong score: %+v", score)
+	}
+
+	if _, err := LoadScorer(strings.NewReader(`{"unknown": 1}`)); err == nil {
+		t.Error("No error for unknown rule")
+	}
+	if _, err := LoadScorer(strings.NewReader(`{"groups": {"NTb": 1, "ntb": 2}}`)); err == nil {
+		t.Error("No error for groups differing only by case")
+	}
+}
+
+func TestGroupRule(t *testing.T) {
+	entry := Entry{Release: release.Parse("The.Expanse.S03E05.720p.WEB.h264-NTb")}
+	for _, rule := range []GroupRule{{"NTb": 5}, {"NTB": 5}, {"ntb": 5}, {"NTb": 5, "ntb": 3}} {
+		if score, reason := rule.Score(entry); score != 5 || reason != "group NTb" {
+			t.Errorf("Wrong score for %v: %f, %s", rule, score, reason)
+		}
+	}
+	rule, err := NewGroupRule(map[string]float64{"NTb": 5})
+	if err != nil {
+		t.Fatalf("Error creating rule: %v", err)
+	}
+	if score, _ := rule.Score(entry); score != 5 {
+		t.Errorf("Wrong score: %f", score)
+	}
+	if _, err := NewGroupRule(map[string]float64{"NTb": 1, "ntb": 2}); err == nil {
+		t.Error("No error for groups differing only by case")
+	}
+}
@@ -0,0 +1,403 @@
+package newznab
+
+import (
+	"context"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/smquartz/errors"
+	nxml "github.com/smquartz/newznab/xml"
+)
+
+// Indexer is the store of entries a Server serves. Methods for functions the
+// store does not support should return ErrFunctionNotAvailable, and methods
+// looking up an entry that does not exist ErrNoSuchItem; other NErrors are
+// passed on to the caller as they are.
+type Indexer interface {
+	// returns the capabilities of the indexer (t=caps)
+	Capabilities(ctx context.Context) (nxml.Capabilities, error)
+	// executes a generic search (t=search)
+	Search(ctx context.Context, q SearchQuery) (Results, error)
+	// executes a TV search (t=tvsearch)
+	TVSearch(ctx context.Context, q TVQuery) (Results, error)
+	// executes a movie search (t=movie)
+	MovieSearch(ctx context.Context, q MovieQuery) (Results, error)
+	// executes a music search (t=music)
+	MusicSearch(ctx context.Context, q MusicQuery) (Results, error)
+	// executes a book search (t=book)
+	BookSearch(ctx context.Context, q BookQuery) (Results, error)
+	// returns the entry with the provided GUID (t=details)
+	Details(ctx context.Context, guid string) (Entry, error)
+	// returns the descriptor file of the entry with the provided GUID (t=get)
+	Get(ctx context.Context, guid string) (File, error)
+}
+
+// Server is an http.Handler that serves the newznab API from an Indexer
+type Server struct {
+	// the store of entries served
+	Indexer Indexer
+	// reports whether an API key may be used; every request is permitted if
+	// nil. Capabilities are served without an API key.
+	Authenticate func(apiKey string) bool
+	// title of the RSS feeds served
+	Title string
+	// description of the RSS feeds served
+	Description string
+}
+
+// NewServer returns a Server that serves the provided Indexer
+func NewServer(indexer Indexer) *Server {
+	return &Server{Indexer: indexer, Title: "newznab", Description: "newznab feed"}
+}
+
+// ServeHTTP implements the http.Handler interface for the Server type
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	function := params.Get("t")
+	if function == "" {
+		writeNError(w, ErrMissingParameter)
+		return
+	}
+	apiKey := params.Get("apikey")
+	if function != "caps" && s.Authenticate != nil && !s.Authenticate(apiKey) {
+		writeNError(w, ErrIncorrectUserCredentials)
+		return
+	}
+
+	ctx := r.Context()
+	links := serverLinks{base: requestBase(r), apiKey: apiKey}
+	var results Results
+	var err error
+	switch function {
+	case "caps":
+		s.serveCapabilities(ctx, w)
+		return
+	case "details":
+		s.serveDetails(ctx, w, params, links)
+		return
+	case "get":
+		s.serveGet(ctx, w, r, params)
+		return
+	case "search":
+		var q SearchQuery
+		if q, err = searchQueryFromValues(params); err == nil {
+			results, err = s.Indexer.Search(ctx, q)
+		}
+	case "tvsearch":
+		var q TVQuery
+		if q, err = tvQueryFromValues(params); err == nil {
+			results, err = s.Indexer.TVSearch(ctx, q)
+		}
+	case "movie":
+		var q MovieQuery
+		if q, err = movieQueryFromValues(params); err == nil {
+			results, err = s.Indexer.MovieSearch(ctx, q)
+		}
+	case "music":
+		var q MusicQuery
+		if q, err = musicQueryFromValues(params); err == nil {
+			results, err = s.Indexer.MusicSearch(ctx, q)
+		}
+	case "book":
+		var q BookQuery
+		if q, err = bookQueryFromValues(params); err == nil {
+			results, err = s.Indexer.BookSearch(ctx, q)
+		}
+	default:
+		err = ErrNoSuchFunction
+	}
+	if err != nil {
+		writeNError(w, err)
+		return
+	}
+	if offset, err := strconv.Atoi(params.Get("offset")); err == nil && results.Offset == 0 {
+		results.Offset = offset
+	}
+	s.writeFeed(w, results, links)
+}
+
+// serveCapabilities writes the capabilities of the indexer
+func (s *Server) serveCapabilities(ctx context.Context, w http.ResponseWriter) {
+	caps, err := s.Indexer.Capabilities(ctx)
+	if err != nil {
+		writeNError(w, err)
+		return
+	}
+	writeXML(w, "text/xml", caps)
+}
+
+// serveDetails writes a feed containing the entry requested
+func (s *Server) serveDetails(ctx context.Context, w http.ResponseWriter, params url.Values, links serverLinks) {
+	guid := params.Get("id")
+	if guid == "" {
+		writeNError(w, ErrMissingParameter)
+		return
+	}
+	entry, err := s.Indexer.Details(ctx, guid)
+	if err != nil {
+		writeNError(w, err)
+		return
+	}
+	s.writeFeed(w, Results{Entries: []Entry{entry}, Total: 1}, links)
+}
+
+// serveGet writes the descriptor file of the entry requested, or redirects to
+// its URL if the indexer does not have its contents
+func (s *Server) serveGet(ctx context.Context, w http.ResponseWriter, r *http.Request, params url.Values) {
+	guid := params.Get("id")
+	if guid == "" {
+		writeNError(w, ErrMissingParameter)
+		return
+	}
+	// the GUID names the file served, so only the form newznab uses is accepted
+	if !hexGUIDPattern.MatchString(guid) {
+		writeNError(w, ErrIncorrectParameter)
+		return
+	}
+	file, err := s.Indexer.Get(ctx, guid)
+	if err != nil {
+		writeNError(w, err)
+		return
+	}
+	raw, err := file.Bytes()
+	if err != nil {
+		if u := file.URL(); u != nil {
+			http.Redirect(w, r, u.String(), http.StatusFound)
+			return
+		}
+		writeNError(w, ErrNoSuchItem)
+		return
+	}
+
+	contentType, extension := nzbContentType, ".nzb"
+	if _, ok := file.(*Torrent); ok {
+		contentType, extension = torrentContentType, ".torrent"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": guid + extension}))
+	w.Write(raw)
+}
+
+// hexGUIDPattern matches a GUID in the form used by newznab, as returned by
+// hexGUID
+var hexGUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// writeFeed writes an RSS feed containing the provided results
+func (s *Server) writeFeed(w http.ResponseWriter, results Results, links serverLinks) {
+	channel := Channel{Title: s.Title, Description: s.Description, Link: links.base}
+	writeXML(w, "application/rss+xml", newRSSFeed(channel, results, links, FeedNewznab))
+}
+
+// writeNError writes a newznab error document describing the provided error,
+// or the NError it wraps; other errors are described as ErrUnknownError
+func writeNError(w http.ResponseWriter, err error) {
+	nerr, ok := nerrorCause(err)
+	if !ok {
+		nerr = ErrUnknownError
+	}
+	writeXML(w, "text/xml", nxml.Error{Code: nerr.Code, Description: nerr.Description})
+}
+
+// nerrorCause returns the NError an error is, or wraps; ok is false if there
+// is none
+func nerrorCause(err error) (nerr NError, ok bool) {
+	for err != nil {
+		if nerr, ok = err.(NError); ok {
+			return nerr, true
+		}
+		switch wrapped := err.(type) {
+		case *errors.Error:
+			err = wrapped.Err
+		case interface{ Unwrap() error }:
+			err = wrapped.Unwrap()
+		default:
+			return nerr, false
+		}
+	}
+	return nerr, false
+}
+
+// writeXML writes a value encoded as an XML document
+func writeXML(w http.ResponseWriter, contentType string, v interface{}) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+// requestBase returns the URL of the API endpoint a request was made to,
+// without its query
+func requestBase(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + r.URL.Path
+}
+
+// serverLinks builds links to the details and descriptor files of entries
+// served by a Server
+type serverLinks struct {
+	// URL of the API endpoint
+	base string
+	// API key the links authenticate with
+	apiKey string
+}
+
+// details implements the rssLinks interface for the serverLinks type
+func (l serverLinks) details(entry Entry) string {
+	return l.link("details", entry)
+}
+
+// download implements the rssLinks interface for the serverLinks type; the
+// entry's own URL is used if it has one
+func (l serverLinks) download(entry Entry) string {
+	if entry.File != nil && entry.File.URL() != nil {
+		return entry.File.URL().String()
+	}
+	return l.link("get", entry)
+}
+
+// link returns a link that calls an API function on an entry
+func (l serverLinks) link(function string, entry Entry) string {
+	query := url.Values{}
+	query.Set("t", function)
+	query.Set("id", hexGUID(entry))
+	if l.apiKey != "" {
+		query.Set("apikey", l.apiKey)
+	}
+	return l.base + "?" + query.Encode()
+}
+
+// intParam returns the value of an integer API parameter, or zero if it is
+// not provided
+func intParam(params url.Values, key string) (int, error) {
+	value := params.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, ErrIncorrectParameter
+	}
+	return i, nil
+}
+
+// pagingParams returns the limit and offset API parameters
+func pagingParams(params url.Values) (limit, offset int, err error) {
+	if limit, err = intParam(params, "limit"); err != nil {
+		return
+	}
+	offset, err = intParam(params, "offset")
+	return
+}
+
+// categoriesParam returns the categories of the cat API parameter
+func categoriesParam(params url.Values) ([]Category, error) {
+	var categories []Category
+	for _, code := range strings.Split(params.Get("cat"), ",") {
+		if code == "" {
+			continue
+		}
+		intCode, err := strconv.Atoi(code)
+		if err != nil {
+			return nil, ErrIncorrectParameter
+		}
+		categories = append(categories, CategoryFromCode(intCode))
+	}
+	return categories, nil
+}
+
+// query is implemented by the query types, which validate themselves
+type query interface {
+	values() (url.Values, error)
+}
+
+// validateQuery validates a query parsed from API parameters
+func validateQuery(q query) error {
+	if _, err := q.values(); err != nil {
+		return ErrIncorrectParameter
+	}
+	return nil
+}
+
+// searchQueryFromValues parses the API parameters of a generic search
+func searchQueryFromValues(params url.Values) (q SearchQuery, err error) {
+	q.Query = params.Get("q")
+	if q.Categories, err = categoriesParam(params); err != nil {
+		return
+	}
+	if q.Limit, q.Offset, err = pagingParams(params); err != nil {
+		return
+	}
+	return q, validateQuery(q)
+}
+
+// tvQueryFromValues parses the API parameters of a TV search
+func tvQueryFromValues(params url.Values) (q TVQuery, err error) {
+	q.Query, q.Season, q.Episode, q.IMDBID = params.Get("q"), params.Get("season"), params.Get("ep"), params.Get("imdbid")
+	ids := map[string]*int64{"rid": &q.TVRageID, "tvdbid": &q.TVDBID, "tvmazeid": &q.TVMazeID}
+	for key, id := range ids {
+		value, err := intParam(params, key)
+		if err != nil {
+			return q, err
+		}
+		*id = int64(value)
+	}
+	if q.Categories, err = categoriesParam(params); err != nil {
+		return
+	}
+	if q.Limit, q.Offset, err = pagingParams(params); err != nil {
+		return
+	}
+	return q, validateQuery(q)
+}
+
+// movieQueryFromValues parses the API parameters of a movie search
+func movieQueryFromValues(params url.Values) (q MovieQuery, err error) {
+	q.Query, q.IMDBID, q.Genre = params.Get("q"), params.Get("imdbid"), params.Get("genre")
+	if q.Categories, err = categoriesParam(params); err != nil {
+		return
+	}
+	if q.Limit, q.Offset, err = pagingParams(params); err != nil {
+		return
+	}
+	return q, validateQuery(q)
+}
+
+// musicQueryFromValues parses the API parameters of a music search
+func musicQueryFromValues(params url.Values) (q MusicQuery, err error) {
+	q.Query, q.Artist, q.Album = params.Get("q"), params.Get("artist"), params.Get("album")
+	q.Label, q.Track, q.Genre = params.Get("label"), params.Get("track"), params.Get("genre")
+	if q.Year, err = intParam(params, "year"); err != nil {
+		return
+	}
+	if q.Categories, err = categoriesParam(params); err != nil {
+		return
+	}
+	if q.Limit, q.Offset, err = pagingParams(params); err != nil {
+		return
+	}
+	return q, validateQuery(q)
+}
+
+// bookQueryFromValues parses the API parameters of a book search
+func bookQueryFromValues(params url.Values) (q BookQuery, err error) {
+	q.Query, q.Title, q.Author = params.Get("q"), params.Get("title"), params.Get("author")
+	if q.Categories, err = categoriesParam(params); err != nil {
+		return
+	}
+	if q.Limit, q.Offset, err = pagingParams(params); err != nil {
+		return
+	}
+	return q, validateQuery(q)
+}
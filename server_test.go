@@ -0,0 +1,164 @@
+package newznab
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/smquartz/errors"
+	nxml "github.com/smquartz/newznab/xml"
+)
+
+// testIndexer is an Indexer serving entries parsed from a sample feed
+type testIndexer struct {
+	entries []Entry
+	caps    nxml.Capabilities
+	// the last TV search executed
+	tvQuery TVQuery
+}
+
+func (i *testIndexer) Capabilities(ctx context.Context) (nxml.Capabilities, error) {
+	return i.caps, nil
+}
+
+func (i *testIndexer) Search(ctx context.Context, q SearchQuery) (Results, error) {
+	end := q.Offset + q.Limit
+	if q.Limit == 0 || end > len(i.entries) {
+		end = len(i.entries)
+	}
+	return Results{Entries: i.entries[q.Offset:end], Offset: q.Offset, Total: len(i.entries)}, nil
+}
+
+func (i *testIndexer) TVSearch(ctx context.Context, q TVQuery) (Results, error) {
+	i.tvQuery = q
+	return Results{Entries: i.entries[:1], Total: 1}, nil
+}
+
+func (i *testIndexer) MovieSearch(ctx context.Context, q MovieQuery) (Results, error) {
+	return Results{}, ErrFunctionNotAvailable
+}
+
+func (i *testIndexer) MusicSearch(ctx context.Context, q MusicQuery) (Results, error) {
+	return Results{}, ErrFunctionNotAvailable
+}
+
+func (i *testIndexer) BookSearch(ctx context.Context, q BookQuery) (Results, error) {
+	return Results{}, ErrFunctionNotAvailable
+}
+
+func (i *testIndexer) Details(ctx context.Context, guid string) (Entry, error) {
+	for _, entry := range i.entries {
+		if hexGUID(entry) == guid {
+			return entry, nil
+		}
+	}
+	return Entry{}, errors.Wrapf(ErrNoSuchItem, "no entry with GUID %s", 1, guid)
+}
+
+func (i *testIndexer) Get(ctx context.Context, guid string) (File, error) {
+	if _, err := i.Details(ctx, guid); err != nil {
+		return nil, err
+	}
+	n := new(NZB)
+	n.raw = []byte("<nzb></nzb>")
+	return n, nil
+}
+
+func newTestIndexer(t *testing.T) *testIndexer {
+	indexer := &testIndexer{entries: entriesFromSample(t, "samples/newznab/newznab_nzb_su.xml", contentAuto)}
+	capsFile, err := os.Open("samples/newznab/newznab_caps.xml")
+	if err != nil {
+		t.Fatalf("Error opening caps: %v", err)
+	}
+	defer capsFile.Close()
+	if err := xml.NewDecoder(capsFile).Decode(&indexer.caps); err != nil {
+		t.Fatalf("Error parsing caps: %v", err)
+	}
+	return indexer
+}
+
+func TestServer(t *testing.T) {
+	indexer := newTestIndexer(t)
+	server := NewServer(indexer)
+	server.Authenticate = func(apiKey string) bool { return apiKey == "xxx" }
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	client, err := NewClient(httpServer.URL, "xxx")
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	ctx := context.Background()
+
+	results, err := client.Search(ctx, SearchQuery{Query: "white collar", Limit: 10, Offset: 5})
+	if err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	if len(results.Entries) != 10 || results.Offset != 5 || results.Total != 100 {
+		t.Fatalf("Wrong results: %d entries, offset %d, total %d", len(results.Entries), results.Offset, results.Total)
+	}
+	served, original := results.Entries[0], indexer.entries[5]
+	if served.Release.Name != original.Release.Name || served.Meta.GUID != original.Meta.GUID || served.File.Size() != original.File.Size() {
+		t.Errorf("Wrong entry: %s, %s, %d", served.Release.Name, served.Meta.GUID, served.File.Size())
+	}
+	if len(served.Meta.Categorisation.Categories) != len(original.Meta.Categorisation.Categories) || !served.Meta.Dates.Published.Equal(original.Meta.Dates.Published) {
+		t.Errorf("Wrong categories or date: %v, %v", served.Meta.Categorisation.Categories, served.Meta.Dates.Published)
+	}
+
+	if _, err := client.TVSearch(ctx, TVQuery{Season: "3", Episode: "5", TVDBID: 108611}); err != nil {
+		t.Fatalf("Error searching: %v", err)
+	}
+	if q := indexer.tvQuery; q.Season != "3" || q.Episode != "5" || q.TVDBID != 108611 {
+		t.Errorf("Wrong TV query: %+v", q)
+	}
+
+	if _, err := client.MovieSearch(ctx, MovieQuery{Query: "matrix"}); err != ErrFunctionNotAvailable {
+		t.Errorf("Wrong error for unavailable function: %v", err)
+	}
+	caps, err := client.RefreshCapabilities(ctx)
+	if err != nil || caps.Limits.Max != indexer.caps.Limits.Max {
+		t.Errorf("Wrong capabilities: %+v, %v", caps.Limits, err)
+	}
+
+	unauthorized, _ := NewClient(httpServer.URL, "yyy")
+	if _, err := unauthorized.Search(ctx, SearchQuery{}); err != ErrIncorrectUserCredentials {
+		t.Errorf("Wrong error for incorrect API key: %v", err)
+	}
+
+	resp, err := http.Get(httpServer.URL + "/api?t=get&apikey=xxx&id=" + hexGUID(indexer.entries[0]))
+	if err != nil {
+		t.Fatalf("Error getting NZB: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.Header.Get("Content-Type") != "application/x-nzb" || string(body) != "<nzb></nzb>" {
+		t.Errorf("Wrong NZB: %s, %s", resp.Header.Get("Content-Type"), body)
+	}
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err != nil || params["filename"] != hexGUID(indexer.entries[0])+".nzb" {
+		t.Errorf("Wrong content disposition: %s", resp.Header.Get("Content-Disposition"))
+	}
+
+	for query, expected := range map[string]error{
+		"":                             ErrMissingParameter,
+		"t=unknown&apikey=xxx":         ErrNoSuchFunction,
+		"t=details&apikey=xxx&id=0":    ErrNoSuchItem,
+		"t=get&apikey=xxx&id=a%22%3Bb": ErrIncorrectParameter,
+		"t=search&apikey=xxx&limit=x":  ErrIncorrectParameter,
+	} {
+		resp, err := http.Get(httpServer.URL + "/api?" + query)
+		if err != nil {
+			t.Fatalf("Error calling %s: %v", query, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err := nerrorFromResponse(body); err != expected {
+			t.Errorf("Wrong error for %s: %v in %s", query, err, strings.TrimSpace(string(body)))
+		}
+	}
+}
@@ -2,9 +2,13 @@ package newznab
 
 import (
 	"bytes"
+	"encoding/hex"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/anacrolix/torrent/metainfo"
+	"github.com/smquartz/errors"
 )
 
 // Torrent is a File implementation that describes a torrent file
@@ -15,24 +19,91 @@ type Torrent struct {
 	passworded bool
 	// URL the torrent file may be downloaded from
 	downloadURL *url.URL
+	// total size of the files in the torrent, as reported by the feed
+	size int64
+	// number of files in the torrent, as reported by the feed
+	numFiles int
+
+	// number of peers seeding the torrent, as reported by the feed
+	seeders int64
+	// number of peers seeding or leeching the torrent, as reported by the feed
+	peers int64
+	// number of peers leeching the torrent, as reported by the feed
+	leechers int64
+	// number of times the torrent has been downloaded, as reported by the feed
+	grabs int64
+	// info hash of the torrent, as reported by the feed
+	infoHash metainfo.Hash
+	// magnet URI for the torrent, as reported by the feed
+	magnetURI *url.URL
+	// the parsed magnet URI for the torrent, from the feed or download URL
+	magnet *Magnet
+	// ratio that must be seeded to, as reported by the feed
+	minimumRatio float64
+	// duration that must be seeded for, as reported by the feed
+	minimumSeedTime time.Duration
+	// factor applied to downloaded data by the tracker, as reported by the feed
+	downloadVolumeFactor *float64
+	// factor applied to uploaded data by the tracker, as reported by the feed
+	uploadVolumeFactor *float64
 }
 
-// Size returns the total size of all the files in the torrent
+// ErrNoMetaInfo is returned when the torrent file of a Torrent is required, but
+// has not been loaded; such as when the Torrent was described by a magnet URI
+var ErrNoMetaInfo = errors.New("torrent file has not been loaded")
+
+// hasMetaInfo returns whether the torrent file has been loaded
+func (t Torrent) hasMetaInfo() bool {
+	return len(t.InfoBytes) > 0
+}
+
+// Size returns the total size of all the files in the torrent. If the torrent
+// file has not been loaded, the size reported by the feed or magnet URI is
+// returned, or -1 if neither is known.
 func (t Torrent) Size() int64 {
-	info, err := t.UnmarshalInfo()
-	if err != nil {
-		return -1
+	if t.hasMetaInfo() {
+		if info, err := t.UnmarshalInfo(); err == nil {
+			return info.TotalLength()
+		}
 	}
-	return info.TotalLength()
+	if t.size != 0 {
+		return t.size
+	}
+	if t.magnet != nil && t.magnet.Length != 0 {
+		return t.magnet.Length
+	}
+	return -1
+}
+
+// setSize sets the total size of all the files in the torrent, as reported by
+// the feed
+func (t *Torrent) setSize(size int64) {
+	t.size = size
 }
 
-// NumFiles returns the total number of files in the torrent
+// NumFiles returns the total number of files in the torrent. If the torrent
+// file has not been loaded, the number reported by the feed is returned, or -1
+// if it is not known.
 func (t Torrent) NumFiles() int {
-	info, err := t.UnmarshalInfo()
-	if err != nil {
-		return -1
+	if t.hasMetaInfo() {
+		if info, err := t.UnmarshalInfo(); err == nil {
+			// single file torrents do not list their files
+			if len(info.Files) == 0 {
+				return 1
+			}
+			return len(info.Files)
+		}
+	}
+	if t.numFiles != 0 {
+		return t.numFiles
 	}
-	return len(info.Files)
+	return -1
+}
+
+// setNumFiles sets the total number of files in the torrent, as reported by
+// the feed
+func (t *Torrent) setNumFiles(numFiles int) {
+	t.numFiles = numFiles
 }
 
 // Passworded returns whether the contents of the torrent file require a password to access
@@ -45,8 +116,12 @@ func (t *Torrent) setPassworded(b bool) {
 	t.passworded = b
 }
 
-// Bytes returns the raw bytes of the torrent file
+// Bytes returns the raw bytes of the torrent file, or ErrNoMetaInfo if it has
+// not been loaded
 func (t Torrent) Bytes() ([]byte, error) {
+	if !t.hasMetaInfo() {
+		return nil, ErrNoMetaInfo
+	}
 	writer := bytes.NewBuffer(nil)
 	err := t.Write(writer)
 	if err != nil {
@@ -55,6 +130,16 @@ func (t Torrent) Bytes() ([]byte, error) {
 	return writer.Bytes(), nil
 }
 
+// load parses a raw torrent file into the Torrent
+func (t *Torrent) load(raw []byte) error {
+	mi, err := metainfo.Load(bytes.NewReader(raw))
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse torrent file", 1)
+	}
+	t.MetaInfo = *mi
+	return nil
+}
+
 // URL returns a URL where the raw torrent file may be downloaded from
 func (t Torrent) URL() *url.URL {
 	return t.downloadURL
@@ -63,4 +148,207 @@ func (t Torrent) URL() *url.URL {
 // setURL sets the URL where the raw torrent file may be downloaded from
 func (t *Torrent) setURL(u *url.URL) {
 	t.downloadURL = u
+	if u != nil && u.Scheme == "magnet" && t.magnet == nil {
+		t.setMagnet(u)
+	}
+}
+
+// setMagnet sets the magnet URI describing the torrent
+func (t *Torrent) setMagnet(u *url.URL) {
+	if magnet, err := ParseMagnet(u.String()); err == nil {
+		t.magnet = &magnet
+	}
+}
+
+// Magnet returns the parsed magnet URI for the torrent; ok is false if no
+// valid magnet URI was provided
+func (t Torrent) Magnet() (magnet Magnet, ok bool) {
+	if t.magnet == nil {
+		return magnet, false
+	}
+	return *t.magnet, true
+}
+
+// Trackers returns the tracker URLs for the torrent, from the torrent file if
+// it has been loaded, or else from its magnet URI
+func (t Torrent) Trackers() []string {
+	if t.hasMetaInfo() {
+		var trackers []string
+		seen := make(map[string]bool)
+		for _, tier := range append([][]string{{t.Announce}}, t.AnnounceList...) {
+			for _, tracker := range tier {
+				if tracker != "" && !seen[tracker] {
+					seen[tracker] = true
+					trackers = append(trackers, tracker)
+				}
+			}
+		}
+		return trackers
+	}
+	if t.magnet != nil {
+		return t.magnet.Trackers
+	}
+	return nil
+}
+
+// Seeders returns the number of peers seeding the torrent, as reported by the
+// feed
+func (t Torrent) Seeders() int64 {
+	return t.seeders
+}
+
+// Peers returns the number of peers seeding or leeching the torrent, as
+// reported by the feed
+func (t Torrent) Peers() int64 {
+	return t.peers
+}
+
+// Leechers returns the number of peers leeching the torrent, as reported by the
+// feed, or else derived from the number of peers and seeders
+func (t Torrent) Leechers() int64 {
+	if t.leechers == 0 && t.peers > t.seeders {
+		return t.peers - t.seeders
+	}
+	return t.leechers
+}
+
+// Grabs returns the number of times the torrent has been downloaded, as
+// reported by the feed
+func (t Torrent) Grabs() int64 {
+	return t.grabs
+}
+
+// InfoHash returns the info hash of the torrent, from the torrent file if it
+// has been loaded, or else as reported by the feed or magnet URI
+func (t Torrent) InfoHash() metainfo.Hash {
+	if t.hasMetaInfo() {
+		return t.HashInfoBytes()
+	}
+	if t.infoHash == (metainfo.Hash{}) && t.magnet != nil {
+		return t.magnet.InfoHash
+	}
+	return t.infoHash
+}
+
+// MagnetURI returns a magnet URI for the torrent, as reported by the feed or
+// used as its download URL; nil if neither is available
+func (t Torrent) MagnetURI() *url.URL {
+	if t.magnetURI != nil {
+		return t.magnetURI
+	}
+	if t.downloadURL != nil && t.downloadURL.Scheme == "magnet" {
+		return t.downloadURL
+	}
+	return nil
+}
+
+// MinimumRatio returns the ratio the tracker requires the torrent be seeded
+// to, as reported by the feed
+func (t Torrent) MinimumRatio() float64 {
+	return t.minimumRatio
+}
+
+// MinimumSeedTime returns how long the tracker requires the torrent be seeded
+// for, as reported by the feed
+func (t Torrent) MinimumSeedTime() time.Duration {
+	return t.minimumSeedTime
+}
+
+// DownloadVolumeFactor returns the factor the tracker applies to data
+// downloaded from the torrent, as reported by the feed; 1 if not reported
+func (t Torrent) DownloadVolumeFactor() float64 {
+	if t.downloadVolumeFactor == nil {
+		return 1
+	}
+	return *t.downloadVolumeFactor
+}
+
+// UploadVolumeFactor returns the factor the tracker applies to data uploaded
+// to the torrent, as reported by the feed; 1 if not reported
+func (t Torrent) UploadVolumeFactor() float64 {
+	if t.uploadVolumeFactor == nil {
+		return 1
+	}
+	return *t.uploadVolumeFactor
+}
+
+// Freeleech returns whether data downloaded from the torrent does not count
+// against the downloader's ratio
+func (t Torrent) Freeleech() bool {
+	return t.DownloadVolumeFactor() == 0
+}
+
+// attrs adds the torznab attributes describing the torrent to a list; grabs
+// are described by the Entry's Meta
+func (t Torrent) attrs(a *rssAttrs) {
+	a.addInt("seeders", t.seeders)
+	a.addInt("peers", t.peers)
+	a.addInt("leechers", t.leechers)
+	if t.infoHash != (metainfo.Hash{}) {
+		a.add("infohash", hex.EncodeToString(t.infoHash[:]))
+	}
+	if t.magnetURI != nil {
+		a.add("magneturl", t.magnetURI.String())
+	}
+	a.addFloat("minimumratio", t.minimumRatio, 64)
+	a.addInt("minimumseedtime", int64(t.minimumSeedTime/time.Second))
+	if t.downloadVolumeFactor != nil {
+		a.add("downloadvolumefactor", strconv.FormatFloat(*t.downloadVolumeFactor, 'f', -1, 64))
+	}
+	if t.uploadVolumeFactor != nil {
+		a.add("uploadvolumefactor", strconv.FormatFloat(*t.uploadVolumeFactor, 'f', -1, 64))
+	}
+}
+
+// setAttr sets the field corresponding to a torznab attribute, and returns
+// whether the attribute was recognised
+func (t *Torrent) setAttr(name, value string) bool {
+	intValue, intErr := strconv.ParseInt(value, 10, 64)
+	floatValue, floatErr := strconv.ParseFloat(value, 64)
+	switch name {
+	case "seeders":
+		if intErr == nil {
+			t.seeders = intValue
+		}
+	case "peers":
+		if intErr == nil {
+			t.peers = intValue
+		}
+	case "leechers":
+		if intErr == nil {
+			t.leechers = intValue
+		}
+	case "grabs":
+		if intErr == nil {
+			t.grabs = intValue
+		}
+	case "infohash":
+		if hash, err := hex.DecodeString(value); err == nil && len(hash) == len(t.infoHash) {
+			copy(t.infoHash[:], hash)
+		}
+	case "magneturl":
+		if u, err := url.Parse(value); err == nil {
+			t.magnetURI = u
+			t.setMagnet(u)
+		}
+	case "minimumratio":
+		if floatErr == nil {
+			t.minimumRatio = floatValue
+		}
+	case "minimumseedtime":
+		if intErr == nil {
+			t.minimumSeedTime = time.Duration(intValue) * time.Second
+		}
+	case "downloadvolumefactor":
+		if floatErr == nil {
+			t.downloadVolumeFactor = &floatValue
+		}
+	case "uploadvolumefactor":
+		if floatErr == nil {
+			t.uploadVolumeFactor = &floatValue
+		}
+	default:
+		return false
+	}
+	return true
 }
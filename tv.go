@@ -1,6 +1,10 @@
 package newznab
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // TV describes the television content contained within an entry
 type TV struct {
@@ -12,6 +16,12 @@ type TV struct {
 	Episode int
 	// ID of the corresponding entry in TVRage
 	TVRageID int64
+	// ID of the corresponding entry in TheTVDB
+	TVDBID int64
+	// ID of the corresponding entry in TVmaze
+	TVMazeID int64
+	// ID of the corresponding entry in IMDB, without the tt prefix
+	IMDBID string
 	// title of the series according to TVRage
 	TVRageTitle string
 	// air date of series according to TVRage
@@ -27,3 +37,56 @@ func (t TV) Title() string {
 func (t TV) ReleaseYear() int {
 	return t.Aired.Year()
 }
+
+// attrs adds the newznab attributes describing the TV Content to a list
+func (t TV) attrs(a *rssAttrs) {
+	a.addInt("season", int64(t.Season))
+	a.addInt("episode", int64(t.Episode))
+	a.addInt("rageid", t.TVRageID)
+	a.addInt("tvdbid", t.TVDBID)
+	a.addInt("tvmazeid", t.TVMazeID)
+	a.add("imdb", t.IMDBID)
+	a.add("tvtitle", t.TVRageTitle)
+	a.addTime("tvairdate", t.Aired)
+	t.TVMovie.attrs(a)
+}
+
+// setAttr sets the field corresponding to a newznab attribute, and returns
+// whether the attribute was recognised
+func (t *TV) setAttr(name, value string) bool {
+	intValue, intErr := strconv.ParseInt(value, 10, 64)
+	switch name {
+	case "season":
+		if season, err := parsePrefixedInt(value, "S"); err == nil {
+			t.Season = season
+		}
+	case "episode":
+		// episodes are sometimes given relative to the season's total, as 5/10
+		if episode, err := parsePrefixedInt(strings.Split(value, "/")[0], "E"); err == nil {
+			t.Episode = episode
+		}
+	case "rageid":
+		if intErr == nil {
+			t.TVRageID = intValue
+		}
+	case "tvdbid":
+		if intErr == nil {
+			t.TVDBID = intValue
+		}
+	case "tvmazeid":
+		if intErr == nil {
+			t.TVMazeID = intValue
+		}
+	case "imdb":
+		t.IMDBID, _ = normaliseIMDBID(value)
+	case "tvtitle":
+		t.TVRageTitle = value
+	case "tvairdate":
+		if aired, err := parseDate(value); err == nil {
+			t.Aired = aired
+		}
+	default:
+		return t.TVMovie.setAttr(name, value)
+	}
+	return true
+}
@@ -12,6 +12,7 @@ import (
 // the output of the t=caps command, which returns information such as details
 // of what is indexed, supported functions, etc
 type Capabilities struct {
+	XMLName xml.Name `xml:"caps"`
 	// describes information about the indexer itself, rather than what
 	// it indexes
 	Server CapabilitiesServer `xml:"server"`
@@ -33,43 +34,136 @@ type Capabilities struct {
 	Genres []CapabilitiesGenre `xml:"genres>genre"`
 }
 
-// SearchCapabilities describes whether a particular kind of search is supported
+// SearchCapabilities describes whether a particular kind of search is
+// supported, and which parameters it accepts
 type SearchCapabilities struct {
+	// whether the indexer described this kind of search at all
+	Declared bool `xml:"-"`
+	// whether this kind of search may be executed
 	Available bool `xml:"available,attr"`
+	// the parameters accepted by this kind of search; nil if the indexer does
+	// not advertise them
+	SupportedParams []SearchParam `xml:"supportedParams,attr"`
 }
 
 // UnmarshalXML enables the unmarshalling of XML into SearchCapabilities
 func (sc *SearchCapabilities) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var search struct {
-		Available string `xml:"available,attr"`
+		Available       string `xml:"available,attr"`
+		SupportedParams string `xml:"supportedParams,attr"`
 	}
 	err := d.DecodeElement(&search, &start)
 	if err != nil {
 		return errors.Wrapf(err, "unable to parse searching element", 1)
 	}
 
-	sc.Available, err = parseYesNoBool(search.Available)
-	if err != nil {
-		return errors.Wrapf(err, "unable to parse available attribute: %s", 1, search.Available)
+	sc.Declared = true
+	if search.Available != "" {
+		sc.Available, err = parseYesNoBool(search.Available)
+		if err != nil {
+			return errors.Wrapf(err, "unable to parse available attribute: %s", 1, search.Available)
+		}
 	}
 
+	sc.SupportedParams = nil
+	for _, param := range strings.Split(search.SupportedParams, ",") {
+		if param = strings.TrimSpace(param); param != "" {
+			sc.SupportedParams = append(sc.SupportedParams, SearchParam(strings.ToLower(param)))
+		}
+	}
+
+	return nil
+}
+
+// MarshalXML enables the marshalling of SearchCapabilities into XML; a kind of
+// search that is neither declared nor available is omitted
+func (sc SearchCapabilities) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if !sc.Declared && !sc.Available {
+		return nil
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "available"}, Value: formatYesNoBool(sc.Available)})
+	if sc.SupportedParams != nil {
+		params := make([]string, len(sc.SupportedParams))
+		for i, param := range sc.SupportedParams {
+			params[i] = string(param)
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "supportedParams"}, Value: strings.Join(params, ",")})
+	}
+	if err := e.EncodeElement(struct{}{}, start); err != nil {
+		return errors.Wrapf(err, "unable to encode %s element", 1, start.Name.Local)
+	}
 	return nil
 }
 
+// Supports returns whether a kind of search accepts the provided parameter. If
+// the indexer does not advertise its supported parameters, every parameter is
+// assumed to be supported by an available search.
+func (sc SearchCapabilities) Supports(param SearchParam) bool {
+	if !sc.Available {
+		return false
+	}
+	if sc.SupportedParams == nil {
+		return true
+	}
+	for _, supported := range sc.SupportedParams {
+		if supported == param {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchType identifies a kind of search described in an indexer's
+// capabilities
+type SearchType int
+
+// kinds of search
+const (
+	SearchGeneral SearchType = iota
+	SearchTV
+	SearchMovie
+	SearchAudio
+	SearchMusic
+	SearchBook
+)
+
+// SearchParam identifies a parameter accepted by a search
+type SearchParam string
+
+// parameters accepted by searches
+const (
+	ParamQuery    SearchParam = "q"
+	ParamSeason   SearchParam = "season"
+	ParamEpisode  SearchParam = "ep"
+	ParamTVRageID SearchParam = "rid"
+	ParamTVDBID   SearchParam = "tvdbid"
+	ParamTVMazeID SearchParam = "tvmazeid"
+	ParamIMDBID   SearchParam = "imdbid"
+	ParamTMDBID   SearchParam = "tmdbid"
+	ParamGenre    SearchParam = "genre"
+	ParamArtist   SearchParam = "artist"
+	ParamAlbum    SearchParam = "album"
+	ParamLabel    SearchParam = "label"
+	ParamTrack    SearchParam = "track"
+	ParamYear     SearchParam = "year"
+	ParamTitle    SearchParam = "title"
+	ParamAuthor   SearchParam = "author"
+)
+
 // CapabilitiesServer describes an indexer itself
 type CapabilitiesServer struct {
 	// the version of the newznab protoc implemented by the server
 	Version string `xml:"version,attr"`
 	// the title of the indexer
-	Title string `xml:"title,attr"`
+	Title string `xml:"title,attr,omitempty"`
 	// a tagline for the indexer
-	Strapline string `xml:"strapline,attr"`
+	Strapline string `xml:"strapline,attr,omitempty"`
 	// a contact email address for the indexer
-	Email string `xml:"email,attr"`
+	Email string `xml:"email,attr,omitempty"`
 	// a website for the indexer
-	URL string `xml:"url,attr"`
+	URL string `xml:"url,attr,omitempty"`
 	// a logo or other image for the indexer
-	Image string `xml:"image,attr"`
+	Image string `xml:"image,attr,omitempty"`
 }
 
 // CapabilitiesLimits describes the limits an indexer imposes on searches
@@ -78,11 +172,27 @@ type CapabilitiesLimits struct {
 	Max int `xml:"max,attr"`
 	// describes the default number of items returned in a search
 	Default int `xml:"default,attr"`
+	// describes the maximum number of items returned in a single page of a
+	// search, if the indexer distinguishes it from Max
+	MaxPageSize int `xml:"maxPageSize,attr,omitempty"`
 }
 
 // CapabilitiesRetention describes the how long an indexer retains content for
 type CapabilitiesRetention struct {
-	Days int `xml:"days,int"`
+	Days int `xml:"days,attr"`
+}
+
+// MarshalXML enables the marshalling of CapabilitiesRetention into XML;
+// retention is omitted if it is not known
+func (cr CapabilitiesRetention) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if cr.Days == 0 {
+		return nil
+	}
+	type retention CapabilitiesRetention
+	if err := e.EncodeElement(retention(cr), start); err != nil {
+		return errors.Wrapf(err, "unable to encode retention element", 1)
+	}
+	return nil
 }
 
 // CapabilitiesRegistration describes whether registration is available for an
@@ -92,6 +202,7 @@ type CapabilitiesRegistration struct {
 	Open      bool `xml:"open,attr"`
 }
 
+// parseYesNoBool parses a boolean attribute, which newznab writes as yes or no
 func parseYesNoBool(str string) (bool, error) {
 	str2 := strings.ToLower(str)
 	str2 = strings.Replace(str2, "yes", "true", -1)
@@ -99,6 +210,26 @@ func parseYesNoBool(str string) (bool, error) {
 	return strconv.ParseBool(str2)
 }
 
+// formatYesNoBool formats a boolean attribute as yes or no
+func formatYesNoBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// MarshalXML enables the marshalling of CapabilitiesRegistration into XML
+func (cr CapabilitiesRegistration) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = append(start.Attr,
+		xml.Attr{Name: xml.Name{Local: "available"}, Value: formatYesNoBool(cr.Available)},
+		xml.Attr{Name: xml.Name{Local: "open"}, Value: formatYesNoBool(cr.Open)},
+	)
+	if err := e.EncodeElement(struct{}{}, start); err != nil {
+		return errors.Wrapf(err, "unable to encode registration element", 1)
+	}
+	return nil
+}
+
 // UnmarshalXML enables the unmarshalling of XML into CapabilitiesRegistration
 func (cr *CapabilitiesRegistration) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var reg struct {
@@ -130,6 +261,33 @@ type CapabilitiesSearching struct {
 	TV      SearchCapabilities `xml:"tv-search"`
 	Movie   SearchCapabilities `xml:"movie-search"`
 	Audio   SearchCapabilities `xml:"audio-search"`
+	Music   SearchCapabilities `xml:"music-search"`
+	Book    SearchCapabilities `xml:"book-search"`
+}
+
+// Search returns the capabilities of the provided kind of search
+func (cs CapabilitiesSearching) Search(search SearchType) SearchCapabilities {
+	switch search {
+	case SearchGeneral:
+		return cs.General
+	case SearchTV:
+		return cs.TV
+	case SearchMovie:
+		return cs.Movie
+	case SearchAudio:
+		return cs.Audio
+	case SearchMusic:
+		return cs.Music
+	case SearchBook:
+		return cs.Book
+	}
+	return SearchCapabilities{}
+}
+
+// Supports returns whether the provided kind of search is available and
+// accepts the provided parameter
+func (c Capabilities) Supports(search SearchType, param SearchParam) bool {
+	return c.Searching.Search(search).Supports(param)
 }
 
 // CapabilitiesCategory describes an individual category indexed by an indexer
@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -79,3 +80,106 @@ func TestCapabilitiesUnmarshalling(t *testing.T) {
 		t.Errorf("Wrong number of genres: %d", len(capabilities.Genres))
 	}
 }
+
+func TestCapabilitiesRetentionUnmarshalling(t *testing.T) {
+	capabilities := new(Capabilities)
+	err := xml.Unmarshal([]byte(`<caps><retention days="3000"/></caps>`), capabilities)
+	if err != nil {
+		t.Errorf("Failed to parse test capabilities XML: %v", err)
+	}
+	if capabilities.Retention.Days != 3000 {
+		t.Errorf("Wrong retention days: %d", capabilities.Retention.Days)
+	}
+}
+
+func TestExtendedCapabilitiesUnmarshalling(t *testing.T) {
+	capabilities := new(Capabilities)
+	err := xml.Unmarshal([]byte(`<caps>
+		<limits max="100" default="50" maxPageSize="100"/>
+		<searching>
+			<search available="yes" supportedParams="q"/>
+			<tv-search available="yes" supportedParams="q,season,ep,imdbid,tvdbid"/>
+			<movie-search available="no" supportedParams="q"/>
+			<music-search available="yes" supportedParams="q,artist,album"/>
+			<book-search available="yes" supportedParams="q,title,author"/>
+		</searching>
+	</caps>`), capabilities)
+	if err != nil {
+		t.Fatalf("Failed to parse test capabilities XML: %v", err)
+	}
+
+	if capabilities.Limits.MaxPageSize != 100 {
+		t.Errorf("Wrong limits max page size: %d", capabilities.Limits.MaxPageSize)
+	}
+	expectedTVParams := []SearchParam{ParamQuery, ParamSeason, ParamEpisode, ParamIMDBID, ParamTVDBID}
+	if !reflect.DeepEqual(capabilities.Searching.TV.SupportedParams, expectedTVParams) {
+		t.Errorf("Wrong tv-search supported params: %v", capabilities.Searching.TV.SupportedParams)
+	}
+
+	supports := []struct {
+		search   SearchType
+		param    SearchParam
+		expected bool
+	}{
+		{SearchTV, ParamTVDBID, true},
+		{SearchTV, ParamTVRageID, false},
+		{SearchMovie, ParamQuery, false},
+		{SearchMusic, ParamArtist, true},
+		{SearchBook, ParamAuthor, true},
+		{SearchAudio, ParamQuery, false},
+	}
+	for _, s := range supports {
+		if capabilities.Supports(s.search, s.param) != s.expected {
+			t.Errorf("Wrong support for %s in search %d: expected %v", s.param, s.search, s.expected)
+		}
+	}
+	if capabilities.Searching.Audio.Declared || !capabilities.Searching.Book.Declared {
+		t.Errorf("Wrong declared searches: audio %v, book %v", capabilities.Searching.Audio.Declared, capabilities.Searching.Book.Declared)
+	}
+}
+
+func TestCapabilitiesMarshalling(t *testing.T) {
+	testCapabilities, err := os.Open("../samples/newznab/newznab_caps.xml")
+	if err != nil {
+		t.Fatalf("Failed to open test capabilities XML file: %v", err)
+	}
+	defer testCapabilities.Close()
+	capabilities := new(Capabilities)
+	if err := xml.NewDecoder(testCapabilities).Decode(capabilities); err != nil {
+		t.Fatalf("Failed to parse test capabilities XML: %v", err)
+	}
+
+	marshalled, err := xml.Marshal(capabilities)
+	if err != nil {
+		t.Fatalf("Failed to marshal capabilities: %v", err)
+	}
+	for _, expected := range []string{`<caps>`, `<registration available="yes" open="no"></registration>`, `<search available="yes"></search>`} {
+		if !strings.Contains(string(marshalled), expected) {
+			t.Errorf("Marshalled capabilities missing %s: %s", expected, marshalled)
+		}
+	}
+	if strings.Contains(string(marshalled), "retention") || strings.Contains(string(marshalled), "book-search") {
+		t.Errorf("Marshalled capabilities include undeclared elements: %s", marshalled)
+	}
+
+	remarshalled := new(Capabilities)
+	if err := xml.Unmarshal(marshalled, remarshalled); err != nil {
+		t.Fatalf("Failed to parse marshalled capabilities: %v", err)
+	}
+	if !reflect.DeepEqual(remarshalled, capabilities) {
+		t.Errorf("Wrong capabilities after round trip: %+v", remarshalled)
+	}
+
+	extended := Capabilities{Retention: CapabilitiesRetention{Days: 3000}}
+	extended.Searching.TV = SearchCapabilities{Available: true, SupportedParams: []SearchParam{ParamQuery, ParamTVDBID}}
+	extended.Searching.Movie = SearchCapabilities{Declared: true}
+	marshalled, err = xml.Marshal(extended)
+	if err != nil {
+		t.Fatalf("Failed to marshal capabilities: %v", err)
+	}
+	for _, expected := range []string{`<retention days="3000"></retention>`, `<tv-search available="yes" supportedParams="q,tvdbid"></tv-search>`, `<movie-search available="no"></movie-search>`} {
+		if !strings.Contains(string(marshalled), expected) {
+			t.Errorf("Marshalled capabilities missing %s: %s", expected, marshalled)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package xml
 
-import "fmt"
+import (
+	"encoding/xml"
+	"fmt"
+)
 
 // errors used in this package
 var ()
@@ -16,6 +19,8 @@ const (
 
 // Error describes a parsed newznab error
 type Error struct {
+	// name of the XML element
+	XMLName xml.Name `xml:"error"`
 	// the error code
 	Code int `xml:"code,attr"`
 	// the error text
@@ -20,12 +20,31 @@ func (t *Time) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	if err := e.EncodeToken(xml.CharData([]byte(t.UTC().Format(time.RFC822)))); err != nil {
 		return errors.Wrapf(err, ErrPrefixUnableEncodeRFC822UTCTime, 1)
 	}
-	if err := e.EncodeToken(xml.EndElement{start.Name}); err != nil {
+	if err := e.EncodeToken(xml.EndElement{Name: start.Name}); err != nil {
 		return errors.Wrapf(err, ErrPrefixUnableEncodeEndElement, 1, start.Name)
 	}
 	return nil
 }
 
+// MarshalXMLAttr defines how to marshal the Time struct into an XML attribute
+func (t Time) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	if t.IsZero() {
+		return xml.Attr{}, nil
+	}
+	return xml.Attr{Name: name, Value: t.Format(time.RFC1123Z)}, nil
+}
+
+// UnmarshalXMLAttr defines how to unmarshal an XML attribute into the Time
+// struct
+func (t *Time) UnmarshalXMLAttr(attr xml.Attr) error {
+	date, err := parseDate(attr.Value)
+	if err != nil {
+		return errors.Wrapf(err, "unable to parse %s attribute", 1, attr.Name.Local)
+	}
+	*t = Time{Time: date}
+	return nil
+}
+
 // UnmarshalXML defines how to unmarshall XML into the Time struct
 func (t *Time) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var raw string